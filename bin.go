@@ -21,7 +21,6 @@ package bin
 
 import (
 	"errors"
-	"github.com/Dviih/bin/buffer"
 	"reflect"
 )
 
@@ -29,6 +28,21 @@ var (
 	Invalid              = errors.New("invalid value")
 	CantSet              = errors.New("can't set")
 	TypeMustBeComparable = errors.New("type must be comparable")
+	UnknownOption        = errors.New("unknown tag option")
+	InvalidKind          = errors.New("invalid kind range")
+	NameExists           = errors.New("name already registered")
+	UnknownName          = errors.New("unknown name")
+	ImplExists           = errors.New("implementation already registered")
+	UnknownImpl          = errors.New("unknown implementation")
+	NotImplemented       = errors.New("type does not implement interface")
+	InvalidPath          = errors.New("invalid path")
+	Mismatch             = errors.New("type mismatch")
+	Overflow             = errors.New("value does not fit")
+	OneOfConflict        = errors.New("more than one field of oneof is set")
+	EnumExists           = errors.New("enum already registered")
+	UnknownEnum          = errors.New("undefined enum value")
+	CantSize             = errors.New("can't size a stream")
+	NotFramed            = errors.New("struct is not framed")
 	unexpectedBehavior   = errors.New("this is a very unexpected behavior")
 )
 
@@ -36,7 +50,7 @@ func Value(v interface{}) reflect.Value {
 	if rv, ok := v.(reflect.Value); ok {
 		return rv
 	} else {
-		return Abs[reflect.Value](reflect.ValueOf(v))
+		return abs(reflect.ValueOf(v))
 	}
 }
 
@@ -54,24 +68,7 @@ func Zero(value reflect.Value) {
 func Abs[T interface{}](t interface{}) T {
 	switch t := t.(type) {
 	case reflect.Value:
-		for {
-			switch t.Kind() {
-			case reflect.Pointer, reflect.Interface:
-				if t.IsZero() {
-					Zero(t)
-				}
-
-				elem := t.Elem()
-
-				if elem.Kind() == reflect.Invalid {
-					return (interface{})(t).(T)
-				}
-
-				t = elem
-			default:
-				return (interface{})(t).(T)
-			}
-		}
+		return (interface{})(abs(t)).(T)
 	case reflect.Type:
 		for {
 			switch t.Kind() {
@@ -86,6 +83,27 @@ func Abs[T interface{}](t interface{}) T {
 	return t.(T)
 }
 
+func abs(value reflect.Value) reflect.Value {
+	for {
+		switch value.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if value.IsZero() {
+				Zero(value)
+			}
+
+			elem := value.Elem()
+
+			if elem.Kind() == reflect.Invalid {
+				return value
+			}
+
+			value = elem
+		default:
+			return value
+		}
+	}
+}
+
 func KeyElem(value reflect.Value) (reflect.Type, reflect.Type) {
 	t := Abs[reflect.Value](value).Type()
 
@@ -107,20 +125,18 @@ func KeyElem(value reflect.Value) (reflect.Type, reflect.Type) {
 }
 
 func Marshal(v interface{}) ([]byte, error) {
-	b := buffer.New()
-	encoder := NewEncoder(b)
-
-	if err := encoder.Encode(v); err != nil {
-		return nil, err
-	}
+	return std.MarshalAppend(nil, v)
+}
 
-	return b.Data(), nil
+// MarshalAppend encodes v appending to dst, when dst has enough capacity nothing is allocated.
+func MarshalAppend(dst []byte, v interface{}) ([]byte, error) {
+	return std.MarshalAppend(dst, v)
 }
 
 func Unmarshal[T interface{}](data []byte) (T, error) {
 	var t T
 
-	if err := NewDecoder(buffer.From(data)).Decode(&t); err != nil {
+	if err := std.Unmarshal(data, &t); err != nil {
 		var zero T
 		return zero, err
 	}
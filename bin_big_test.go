@@ -0,0 +1,109 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+//go:build !dviih_bin_kind_big
+
+package bin
+
+import (
+	"math/big"
+	"testing"
+)
+
+type StructBig struct {
+	Float *big.Float `bin:"10"`
+	Small *big.Float `bin:"20"`
+	Inf   *big.Float `bin:"30"`
+	Rat   *big.Rat   `bin:"40"`
+	Zero  big.Rat    `bin:"50"`
+}
+
+var StructBigValue = &StructBig{
+	Float: new(big.Float).SetPrec(200).SetMode(big.AwayFromZero).Quo(big.NewFloat(-1), big.NewFloat(3)),
+	Small: new(big.Float).SetMantExp(big.NewFloat(0.75), -1<<20),
+	Inf:   new(big.Float).SetInf(true),
+	Rat:   big.NewRat(-22, 7),
+}
+
+func TestBig(t *testing.T) {
+	data, err := Marshal(StructBigValue)
+	if err != nil {
+		t.Error("failed to marshal big")
+	}
+
+	st, err := Unmarshal[*StructBig](data)
+	if err != nil {
+		t.Error("failed to unmarshal big")
+	}
+
+	for _, f := range [][2]*big.Float{{st.Float, StructBigValue.Float}, {st.Small, StructBigValue.Small}, {st.Inf, StructBigValue.Inf}} {
+		if f[0].Cmp(f[1]) != 0 || f[0].Prec() != f[1].Prec() || f[0].Mode() != f[1].Mode() {
+			t.Errorf("expected %v, received: %v", f[1], f[0])
+		}
+	}
+
+	if st.Rat.Cmp(StructBigValue.Rat) != 0 || st.Zero.Sign() != 0 {
+		t.Errorf("expected %v, received: %v", StructBigValue.Rat, st.Rat)
+	}
+}
+
+func TestBigGob(t *testing.T) {
+	f, _ := StructBigValue.Float.GobEncode()
+
+	data, err := Marshal(f)
+	if err != nil {
+		t.Error("failed to marshal float gob")
+	}
+
+	float, err := Unmarshal[*big.Float](data)
+	if err != nil {
+		t.Error("failed to unmarshal float gob")
+	}
+
+	if float.Cmp(StructBigValue.Float) != 0 || float.Prec() != StructBigValue.Float.Prec() {
+		t.Errorf("expected %v, received: %v", StructBigValue.Float, float)
+	}
+
+	r, _ := StructBigValue.Rat.GobEncode()
+
+	if data, err = Marshal(r); err != nil {
+		t.Error("failed to marshal rat gob")
+	}
+
+	rat, err := Unmarshal[*big.Rat](data)
+	if err != nil {
+		t.Error("failed to unmarshal rat gob")
+	}
+
+	if rat.Cmp(StructBigValue.Rat) != 0 {
+		t.Errorf("expected %v, received: %v", StructBigValue.Rat, rat)
+	}
+}
+
+func TestBigGobInvalid(t *testing.T) {
+	for _, data := range [][]byte{
+		{255, 255, 255, 255, 255, 255, 255, 255, 255, 1},
+		{128, 128, 128, 128, 4},
+		{100, 1, 2, 3},
+	} {
+		if _, err := Unmarshal[*big.Float](data); err == nil {
+			t.Errorf("expected an error for %v", data)
+		}
+	}
+}
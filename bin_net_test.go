@@ -0,0 +1,84 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+//go:build !dviih_bin_kind_net
+
+package bin
+
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type StructNet struct {
+	Addr     netip.Addr       `bin:"10"`
+	AddrPort netip.AddrPort   `bin:"20"`
+	Prefix   netip.Prefix     `bin:"30"`
+	IP       net.IP           `bin:"40"`
+	MAC      net.HardwareAddr `bin:"50"`
+	URL      *url.URL         `bin:"60"`
+	Regexp   *regexp.Regexp   `bin:"70"`
+	Zero     netip.AddrPort   `bin:"80"`
+}
+
+var StructNetValue = &StructNet{
+	Addr:     netip.MustParseAddr("fe80::1%eth0"),
+	AddrPort: netip.MustParseAddrPort("10.0.0.1:8080"),
+	Prefix:   netip.MustParsePrefix("192.168.0.0/16"),
+	IP:       net.ParseIP("2001:db8::1"),
+	MAC:      net.HardwareAddr{0, 1, 2, 3, 4, 5},
+	URL:      &url.URL{Scheme: "https", Host: "example.com", Path: "/bin", RawQuery: "a=1"},
+	Regexp:   regexp.MustCompile("^b[i]n$"),
+}
+
+func TestNet(t *testing.T) {
+	data, err := Marshal(StructNetValue)
+	if err != nil {
+		t.Error("failed to marshal net")
+	}
+
+	st, err := Unmarshal[*StructNet](data)
+	if err != nil {
+		t.Error("failed to unmarshal net")
+	}
+
+	if !reflect.DeepEqual(st, StructNetValue) {
+		t.Errorf("expected %v, received: %v", StructNetValue, st)
+	}
+}
+
+func TestNetInterface(t *testing.T) {
+	data, err := Marshal(Interface(StructNetValue))
+	if err != nil {
+		t.Error("failed to marshal net interface")
+	}
+
+	st, err := UnmarshalAs[*StructNet](data)
+	if err != nil {
+		t.Error("failed to unmarshal net interface")
+	}
+
+	if !reflect.DeepEqual(st, StructNetValue) {
+		t.Errorf("expected %v, received: %v", StructNetValue, st)
+	}
+}
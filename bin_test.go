@@ -20,7 +20,12 @@
 package bin
 
 import (
+	"errors"
+	"github.com/Dviih/bin/buffer"
+	"github.com/Dviih/bin/kind"
+	"io"
 	"reflect"
+	"slices"
 	"testing"
 )
 
@@ -56,6 +61,30 @@ type StructMap struct {
 	Stuff map[interface{}]interface{} `bin:"20"`
 }
 
+type StructFixed struct {
+	Float   float64    `bin:"10,fixed"`
+	Floats  []float32  `bin:"20,fixed"`
+	Complex complex128 `bin:"30"`
+	Zero    float64    `bin:"40,fixed"`
+}
+
+type StructPacked struct {
+	Flags []bool   `bin:"10,packed"`
+	Array [10]bool `bin:"20,packed"`
+	Plain []bool   `bin:"30"`
+}
+
+type StructDelta struct {
+	IDs   []uint64 `bin:"10,delta"`
+	Times []int64  `bin:"20,delta"`
+	Array [3]int32 `bin:"30,delta"`
+}
+
+type StructStream struct {
+	Name   string   `bin:"10"`
+	Values chan int `bin:"20,stream"`
+}
+
 type StructAll struct {
 	One   *Struct1
 	Two   *StructNumbers
@@ -86,6 +115,9 @@ var (
 	Slice   = []int{24, 69, 128, 512}
 	Slice2  = []interface{}{"twenty", 50, "hundreds"}
 	String  = "Hello, World!"
+	Bytes   = []byte("Hello, World!")
+	Bytes2  = [4]byte{1, 128, 255, 0}
+	Floats  = []float64{Float, Float}
 	Struct2 = &Struct1{
 		FieldOne: "one",
 		FieldTwo: 2,
@@ -118,6 +150,21 @@ var (
 			81: "nine",
 		},
 	}
+	StructFixedValue = &StructFixed{
+		Float:   13.69,
+		Floats:  []float32{1.5, 0.1},
+		Complex: complex(2, 4),
+	}
+	StructPackedValue = &StructPacked{
+		Flags: []bool{true, false, true, true, false, false, false, false, true},
+		Array: [10]bool{9: true},
+		Plain: []bool{true, false},
+	}
+	StructDeltaValue = &StructDelta{
+		IDs:   []uint64{1000, 1001, 1003, 1 << 63},
+		Times: []int64{1700000000, 1700000060, 1699999990},
+		Array: [3]int32{-1, 5, 3},
+	}
 	StructAllValue = &StructAll{
 		One:   Struct2,
 		Two:   StructNumbersValue,
@@ -135,6 +182,14 @@ var (
 	expectedMap           = []byte{1, 16, 128, 8}
 	expectedSlice         = []byte{4, 24, 69, 128, 1, 128, 4}
 	expectedString        = []byte{13, 72, 101, 108, 108, 111, 44, 32, 87, 111, 114, 108, 100, 33}
+	expectedBytes         = expectedString
+	expectedBytes2        = []byte{1, 128, 255, 0}
+	expectedFloats        = append(append([]byte{2}, expectedFloat...), expectedFloat...)
+	expectedStructPacked  = []byte{10, 9, 13, 1, 20, 0, 2, 30, 2, 255, 0}
+	expectedStructStream  = []byte{10, 3, 98, 105, 110, 20, 1, 1, 1, 2, 1, 3, 0}
+	expectedStructDelta   = []byte{10, 4, 208, 15, 2, 4, 170, 240, 255, 255, 255, 255, 255, 255, 255, 1, 20, 3, 128, 196, 159, 213, 12, 120, 139, 1, 30, 1, 12, 3}
+	expectedFixedFloat    = []byte{184, 30, 133, 235, 81, 88, 69, 64}
+	expectedStructFixed   = []byte{10, 225, 122, 20, 174, 71, 97, 43, 64, 20, 2, 0, 0, 192, 63, 205, 204, 204, 61, 30, 128, 128, 128, 128, 128, 128, 128, 128, 64, 128, 128, 128, 128, 128, 128, 128, 136, 64, 40, 0, 0, 0, 0, 0, 0, 0, 0}
 	expectedStruct        = []byte{100, 3, 111, 110, 101, 200, 1, 2}
 	expectedStructNumbers = []byte{10, 1, 20, 2, 30, 4, 40, 8, 50, 16, 60, 32, 70, 64, 80, 128, 1, 90, 128, 2, 100, 128, 4, 110, 138, 174, 143, 137, 4, 120, 251, 168, 184, 189, 148, 220, 158, 154, 64, 130, 1, 128, 128, 128, 145, 4, 128, 128, 128, 150, 4, 140, 1, 128, 128, 128, 128, 128, 128, 144, 170, 64, 128, 128, 128, 128, 128, 128, 192, 171, 64}
 	expectedStructArray   = []byte{10, 4, 3, 9, 27, 81, 20, 4, 24, 5, 72, 101, 108, 108, 111, 2, 13, 24, 5, 87, 111, 114, 108, 100, 24, 1, 33}
@@ -170,40 +225,1647 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
-func TestUnmarshal(t *testing.T) {
-	st, err := Unmarshal[*StructNumbers](expectedStructNumbers)
+func TestMarshalAppend(t *testing.T) {
+	prefix := []byte{1, 2, 3}
+
+	data, err := MarshalAppend(prefix, Struct2)
 	if err != nil {
-		t.Error("failed to unmarshal")
+		t.Error("failed to marshal append")
 	}
 
-	if !reflect.DeepEqual(st, StructNumbersValue) {
-		t.Errorf("expected %v, received: %v", StructNumbersValue, st)
-		return
+	expected := append([]byte{1, 2, 3}, expectedStruct...)
+
+	if string(data) != string(expected) {
+		t.Errorf("expected %v, received: %v", expected, data)
 	}
 }
 
-func BenchmarkEncode(b *testing.B) {
-	data, err := Marshal(StructAllValue)
+func TestMarshalAppendAllocs(t *testing.T) {
+	dst := make([]byte, 0, 256)
+
+	n := testing.AllocsPerRun(100, func() {
+		if _, err := MarshalAppend(dst[:0], StructNumbersValue); err != nil {
+			t.Error("failed to marshal append")
+		}
+	})
+
+	if n != 0 {
+		t.Errorf("expected 0 allocations, received: %v", n)
+	}
+}
+
+func TestSize(t *testing.T) {
+	for _, v := range []interface{}{StructAllValue, Interface(StructAllValue), Slice, Floats, Map2} {
+		data, err := Marshal(v)
+		if err != nil {
+			t.Error("failed to marshal")
+		}
+
+		n, err := Size(v)
+		if err != nil {
+			t.Error("failed to size")
+		}
+
+		if n != len(data) {
+			t.Errorf("expected %v, received: %v", len(data), n)
+		}
+	}
+}
+
+func TestBufferMax(t *testing.T) {
+	for _, v := range []interface{}{"bin", []byte("bin"), uint64(1 << 20)} {
+		b := &buffer.Buffer{Max: 2}
+
+		if err := NewEncoder(b).Encode(v); err != io.ErrShortWrite {
+			t.Errorf("expected %v, received: %v", io.ErrShortWrite, err)
+		}
+
+		if b.Len() > b.Max {
+			t.Errorf("expected at most %v bytes, received: %v", b.Max, b.Len())
+		}
+	}
+}
+
+func TestSizeStream(t *testing.T) {
+	c := make(chan int, 3)
+	c <- 1
+	c <- 2
+	close(c)
+
+	st := &StructStream{Name: "bin", Values: c}
+
+	if _, err := Size(st); !errors.Is(err, CantSize) {
+		t.Errorf("expected %v, received: %v", CantSize, err)
+	}
+
+	if _, err := NewCodec(Stream()).Size(c); !errors.Is(err, CantSize) {
+		t.Errorf("expected %v, received: %v", CantSize, err)
+	}
+
+	if len(c) != 2 {
+		t.Errorf("expected %v elements left, received: %v", 2, len(c))
+	}
+}
+
+func TestCodecSize(t *testing.T) {
+	codec := NewCodec(Fixed())
+
+	for _, v := range []interface{}{1.5, StructAllValue, Interface(Floats)} {
+		data, err := codec.Marshal(v)
+		if err != nil {
+			t.Error("failed to marshal")
+		}
+
+		n, err := codec.Size(v)
+		if err != nil {
+			t.Error("failed to size")
+		}
+
+		if n != len(data) {
+			t.Errorf("expected %v, received: %v", len(data), n)
+		}
+	}
+}
+
+func TestFixed(t *testing.T) {
+	data, err := Marshal(StructFixedValue)
 	if err != nil {
-		b.Error("failed to encode (bench)")
+		t.Error("failed to marshal fixed")
 	}
 
-	b.StopTimer()
+	if string(data) != string(expectedStructFixed) {
+		t.Errorf("expected %v, received: %v", expectedStructFixed, data)
+	}
 
-	if string(data) != string(expectedStructAll) {
-		b.Error("not equal (bench)")
+	st, err := Unmarshal[*StructFixed](data)
+	if err != nil {
+		t.Error("failed to unmarshal fixed")
+	}
+
+	if !reflect.DeepEqual(st, StructFixedValue) {
+		t.Errorf("expected %v, received: %v", StructFixedValue, st)
 	}
 }
 
-func BenchmarkDecode(b *testing.B) {
-	sa, err := Unmarshal[*StructAll](expectedStructAll)
+func TestFixedInterface(t *testing.T) {
+	data, err := Marshal(Interface(StructFixedValue))
 	if err != nil {
-		b.Errorf("failed to unmarshalas (bench): %v", err)
+		t.Error("failed to marshal fixed interface")
 	}
 
-	b.StopTimer()
+	st, err := UnmarshalAs[*StructFixed](data)
+	if err != nil {
+		t.Error("failed to unmarshal fixed interface")
+	}
 
-	if !reflect.DeepEqual(sa, StructAllValue) {
-		b.Error("not equal (bench)")
+	if !reflect.DeepEqual(st, StructFixedValue) {
+		t.Errorf("expected %v, received: %v", StructFixedValue, st)
+	}
+}
+
+func TestPacked(t *testing.T) {
+	data, err := Marshal(StructPackedValue)
+	if err != nil {
+		t.Error("failed to marshal packed")
+	}
+
+	if string(data) != string(expectedStructPacked) {
+		t.Errorf("expected %v, received: %v", expectedStructPacked, data)
+	}
+
+	st, err := Unmarshal[*StructPacked](data)
+	if err != nil {
+		t.Error("failed to unmarshal packed")
+	}
+
+	if !reflect.DeepEqual(st, StructPackedValue) {
+		t.Errorf("expected %v, received: %v", StructPackedValue, st)
+	}
+}
+
+func TestPackedInterface(t *testing.T) {
+	data, err := Marshal(Interface(StructPackedValue))
+	if err != nil {
+		t.Error("failed to marshal packed interface")
+	}
+
+	st, err := UnmarshalAs[*StructPacked](data)
+	if err != nil {
+		t.Error("failed to unmarshal packed interface")
+	}
+
+	if !reflect.DeepEqual(st, StructPackedValue) {
+		t.Errorf("expected %v, received: %v", StructPackedValue, st)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	data, err := Marshal(StructDeltaValue)
+	if err != nil {
+		t.Error("failed to marshal delta")
+	}
+
+	if string(data) != string(expectedStructDelta) {
+		t.Errorf("expected %v, received: %v", expectedStructDelta, data)
+	}
+
+	st, err := Unmarshal[*StructDelta](data)
+	if err != nil {
+		t.Error("failed to unmarshal delta")
+	}
+
+	if !reflect.DeepEqual(st, StructDeltaValue) {
+		t.Errorf("expected %v, received: %v", StructDeltaValue, st)
+	}
+}
+
+func TestDeltaInterface(t *testing.T) {
+	data, err := Marshal(Interface(StructDeltaValue))
+	if err != nil {
+		t.Error("failed to marshal delta interface")
+	}
+
+	st, err := UnmarshalAs[*StructDelta](data)
+	if err != nil {
+		t.Error("failed to unmarshal delta interface")
+	}
+
+	if !reflect.DeepEqual(st, StructDeltaValue) {
+		t.Errorf("expected %v, received: %v", StructDeltaValue, st)
+	}
+}
+
+func TestDeltaMapInterface(t *testing.T) {
+	for _, c := range []struct {
+		option Option
+		value  interface{}
+	}{
+		{Delta(), map[[2]int64]string{{5, 7}: "x"}},
+		{Delta(), map[[2]int64][]string{{5, 7}: {"x"}}},
+		{Packed(), map[[2]bool]bool{{true, false}: true}},
+	} {
+		b := buffer.New()
+
+		if err := NewEncoder(b, c.option).Encode(Interface(c.value)); err != nil {
+			t.Fatalf("failed to encode %v: %v", c.value, err)
+		}
+
+		var i interface{}
+		if err := NewDecoder(b, c.option).Decode(&i); err != nil {
+			t.Fatalf("failed to decode %v: %v", c.value, err)
+		}
+
+		if !reflect.DeepEqual(i, c.value) {
+			t.Errorf("expected %v, received: %v", c.value, i)
+		}
+	}
+}
+
+func produce(n int) chan int {
+	c := make(chan int)
+
+	go func() {
+		for i := 1; i <= n; i++ {
+			c <- i
+		}
+
+		close(c)
+	}()
+
+	return c
+}
+
+func TestStream(t *testing.T) {
+	data, err := Marshal(&StructStream{Name: "bin", Values: produce(3)})
+	if err != nil {
+		t.Error("failed to marshal stream")
+	}
+
+	if string(data) != string(expectedStructStream) {
+		t.Errorf("expected %v, received: %v", expectedStructStream, data)
+	}
+
+	st, err := Unmarshal[*StructStream](data)
+	if err != nil {
+		t.Error("failed to unmarshal stream")
+	}
+
+	var values []int
+	for v := range st.Values {
+		values = append(values, v)
+	}
+
+	if st.Name != "bin" || !reflect.DeepEqual(values, []int{1, 2, 3}) {
+		t.Errorf("expected %v, received: %v", []int{1, 2, 3}, values)
+	}
+}
+
+func TestStreamSend(t *testing.T) {
+	st := &StructStream{Values: make(chan int)}
+	done := make(chan []int)
+
+	go func() {
+		var values []int
+		for v := range st.Values {
+			values = append(values, v)
+		}
+
+		done <- values
+	}()
+
+	if err := NewDecoder(buffer.From(expectedStructStream)).Decode(st); err != nil {
+		t.Error("failed to decode stream")
+	}
+
+	if values := <-done; !reflect.DeepEqual(values, []int{1, 2, 3}) {
+		t.Errorf("expected %v, received: %v", []int{1, 2, 3}, values)
+	}
+}
+
+func TestStreamInterface(t *testing.T) {
+	b := buffer.New()
+
+	if err := NewEncoder(b, Stream()).Encode(Interface(produce(3))); err != nil {
+		t.Error("failed to encode stream interface")
+	}
+
+	i, err := Unmarshal[interface{}](b.Data())
+	if err != nil {
+		t.Error("failed to unmarshal stream interface")
+	}
+
+	var values []int
+	for v := range i.(chan int) {
+		values = append(values, v)
+	}
+
+	if !reflect.DeepEqual(values, []int{1, 2, 3}) {
+		t.Errorf("expected %v, received: %v", []int{1, 2, 3}, values)
+	}
+}
+
+func TestValues(t *testing.T) {
+	b := buffer.New()
+	encoder := NewEncoder(b)
+
+	for _, v := range []*Struct1{Struct2, {FieldOne: "bin"}} {
+		if err := encoder.Encode(v); err != nil {
+			t.Error("failed to encode values")
+		}
+	}
+
+	var values []*Struct1
+
+	for v, err := range Values[*Struct1](NewDecoder(b)) {
+		if err != nil {
+			t.Errorf("failed to decode values: %v", err)
+		}
+
+		values = append(values, v)
+	}
+
+	if !reflect.DeepEqual(values, []*Struct1{Struct2, {FieldOne: "bin"}}) {
+		t.Errorf("expected %v, received: %v", []*Struct1{Struct2, {FieldOne: "bin"}}, values)
+	}
+}
+
+func TestValuesUnexpectedEOF(t *testing.T) {
+	data, err := Marshal(Struct2)
+	if err != nil {
+		t.Error("failed to marshal values")
+	}
+
+	var received error
+
+	for _, err := range Values[*Struct1](NewDecoder(buffer.From(data[:len(data)-1]))) {
+		received = err
+	}
+
+	if received != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v, received: %v", io.ErrUnexpectedEOF, received)
+	}
+}
+
+func TestEncodeSeq(t *testing.T) {
+	b := buffer.New()
+
+	if err := EncodeSeq(NewEncoder(b), slices.Values([]int{1, 2, 3})); err != nil {
+		t.Error("failed to encode seq")
+	}
+
+	var c chan int
+
+	if err := NewDecoder(b, Stream()).Decode(&c); err != nil {
+		t.Error("failed to decode seq")
+	}
+
+	var values []int
+	for v := range c {
+		values = append(values, v)
+	}
+
+	if !reflect.DeepEqual(values, []int{1, 2, 3}) {
+		t.Errorf("expected %v, received: %v", []int{1, 2, 3}, values)
+	}
+}
+
+func TestDecodeSeq(t *testing.T) {
+	b := buffer.New()
+
+	if err := EncodeSeq(NewEncoder(b), slices.Values([]*Struct1{Struct2, {FieldOne: "bin"}})); err != nil {
+		t.Error("failed to encode seq")
+	}
+
+	data := slices.Clone(b.Data())
+
+	var values []*Struct1
+
+	for v, err := range DecodeSeq[*Struct1](NewDecoder(b)) {
+		if err != nil {
+			t.Errorf("failed to decode seq: %v", err)
+		}
+
+		values = append(values, v)
+	}
+
+	if !reflect.DeepEqual(values, []*Struct1{Struct2, {FieldOne: "bin"}}) {
+		t.Errorf("expected %v, received: %v", []*Struct1{Struct2, {FieldOne: "bin"}}, values)
+	}
+
+	var received error
+
+	for _, err := range DecodeSeq[*Struct1](NewDecoder(buffer.From(data[:len(data)-1]))) {
+		received = err
+	}
+
+	if received != io.ErrUnexpectedEOF {
+		t.Errorf("expected %v, received: %v", io.ErrUnexpectedEOF, received)
+	}
+}
+
+type Celsius struct {
+	Degrees int
+}
+
+func celsius(offset int) kind.Handler {
+	return kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			return encoder.Encode(value.Interface().(Celsius).Degrees + offset)
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var n int
+
+			if err := decoder.Decode(&n); err != nil {
+				return err
+			}
+
+			value.Set(reflect.ValueOf(Celsius{Degrees: n - offset}))
+			return nil
+		},
+	)
+}
+
+func TestCodec(t *testing.T) {
+	a, b := NewCodec(), NewCodec(Fixed())
+
+	a.Register(200, reflect.TypeFor[Celsius](), celsius(0))
+	b.Register(200, reflect.TypeFor[Celsius](), celsius(100))
+
+	for _, codec := range []*Codec{a, b} {
+		data, err := codec.Marshal(codec.Interface(Celsius{Degrees: 20}))
+		if err != nil {
+			t.Error("failed to marshal codec")
+		}
+
+		var i interface{}
+		if err = codec.Unmarshal(data, &i); err != nil {
+			t.Error("failed to unmarshal codec")
+		}
+
+		if i != (Celsius{Degrees: 20}) {
+			t.Errorf("expected %v, received: %v", Celsius{Degrees: 20}, i)
+		}
+	}
+
+	if data, _ := a.Marshal(Celsius{Degrees: 20}); string(data) != string([]byte{20}) {
+		t.Errorf("expected %v, received: %v", []byte{20}, data)
+	}
+
+	if data, _ := b.Marshal(Celsius{Degrees: 20}); string(data) != string([]byte{120}) {
+		t.Errorf("expected %v, received: %v", []byte{120}, data)
+	}
+
+	if data, _ := b.Marshal(Float); string(data) != string(expectedFixedFloat) {
+		t.Errorf("expected %v, received: %v", expectedFixedFloat, data)
+	}
+
+	// The package functions don't know about kinds of other codecs.
+	if data, _ := Marshal(Celsius{Degrees: 20}); string(data) != string([]byte{1, 20}) {
+		t.Errorf("expected %v, received: %v", []byte{1, 20}, data)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	codec := NewCodec()
+
+	// Marshaling before registering caches Celsius as a struct.
+	if data, _ := codec.Marshal(Celsius{Degrees: 20}); string(data) != string([]byte{1, 20}) {
+		t.Errorf("expected %v, received: %v", []byte{1, 20}, data)
+	}
+
+	if err := codec.Register(200, reflect.TypeFor[Celsius](), celsius(0)); err != nil {
+		t.Errorf("failed to register: %v", err)
+	}
+
+	if data, _ := codec.Marshal(Celsius{Degrees: 20}); string(data) != string([]byte{20}) {
+		t.Errorf("expected %v, received: %v", []byte{20}, data)
+	}
+
+	for _, test := range []struct {
+		err      error
+		expected error
+	}{
+		{codec.Register(100, reflect.TypeFor[Struct1](), celsius(0)), InvalidKind},
+		{codec.Register(200, reflect.TypeFor[Struct1](), celsius(0)), kind.KindExists},
+		{codec.Register(201, reflect.TypeFor[*Celsius](), celsius(0)), kind.TypeExists},
+		{codec.Alias(202, reflect.TypeFor[Struct1]()), kind.UnknownKind},
+		{codec.Alias(200, reflect.TypeFor[Struct1]()), nil},
+	} {
+		if !errors.Is(test.err, test.expected) {
+			t.Errorf("expected %v, received: %v", test.expected, test.err)
+		}
+	}
+
+	var list []kind.Data
+
+	for _, data := range codec.Registered() {
+		if data.Kind == 200 {
+			list = append(list, data)
+		}
+	}
+
+	if len(list) != 2 || list[0].Type != reflect.TypeFor[Celsius]() || list[1].Type != reflect.TypeFor[Struct1]() {
+		t.Errorf("expected Celsius and Struct1 as 200, received: %v", list)
+	}
+
+	if err := codec.Unregister(200); err != nil {
+		t.Errorf("failed to unregister: %v", err)
+	}
+
+	if err := codec.Unregister(200); !errors.Is(err, kind.UnknownKind) {
+		t.Errorf("expected %v, received: %v", kind.UnknownKind, err)
+	}
+
+	if data, _ := codec.Marshal(Celsius{Degrees: 20}); string(data) != string([]byte{1, 20}) {
+		t.Errorf("expected %v, received: %v", []byte{1, 20}, data)
+	}
+}
+
+type EventA struct {
+	ID int `bin:"10"`
+}
+
+type EventB struct {
+	Name string `bin:"10"`
+}
+
+type Bus struct {
+	Event  interface{}            `bin:"10"`
+	Events []interface{}          `bin:"20"`
+	ByName map[string]interface{} `bin:"30"`
+}
+
+func TestRegisterName(t *testing.T) {
+	codec := NewCodec()
+
+	if err := codec.RegisterName("a", reflect.TypeFor[*EventA]()); err != nil {
+		t.Errorf("failed to register name: %v", err)
+	}
+
+	if err := codec.RegisterName("b", reflect.TypeFor[EventB]()); err != nil {
+		t.Errorf("failed to register name: %v", err)
+	}
+
+	if err := codec.RegisterName("a", reflect.TypeFor[Struct1]()); !errors.Is(err, NameExists) {
+		t.Errorf("expected %v, received: %v", NameExists, err)
+	}
+
+	if err := codec.RegisterName("c", reflect.TypeFor[EventA]()); !errors.Is(err, kind.TypeExists) {
+		t.Errorf("expected %v, received: %v", kind.TypeExists, err)
+	}
+
+	bus := &Bus{
+		Event:  &EventA{ID: 1},
+		Events: []interface{}{&EventA{ID: 2}, EventB{Name: "bin"}},
+		ByName: map[string]interface{}{"b": EventB{Name: "map"}},
+	}
+
+	data, err := codec.Marshal(bus)
+	if err != nil {
+		t.Error("failed to marshal named")
+	}
+
+	var st *Bus
+	if err = codec.Unmarshal(data, &st); err != nil {
+		t.Errorf("failed to unmarshal named: %v", err)
+	}
+
+	if !reflect.DeepEqual(st, bus) {
+		t.Errorf("expected %v, received: %v", bus, st)
+	}
+
+	if data, err = codec.Marshal(codec.Interface(bus)); err != nil {
+		t.Error("failed to marshal named interface")
+	}
+
+	var i interface{}
+	if err = codec.Unmarshal(data, &i); err != nil {
+		t.Errorf("failed to unmarshal named interface: %v", err)
+	}
+
+	if v, _ := i.(*Struct).Get(10); !reflect.DeepEqual(v, bus.Event) {
+		t.Errorf("expected %v, received: %v", bus.Event, v)
+	}
+
+	// Other codecs don't know the names.
+	var unknown interface{}
+	if err = NewCodec().Unmarshal(data, &unknown); !errors.Is(err, UnknownName) {
+		t.Errorf("expected %v, received: %v", UnknownName, err)
+	}
+}
+
+func TestRegisterNameID(t *testing.T) {
+	codec := NewCodec()
+
+	if err := codec.RegisterNameID(1, reflect.TypeFor[*EventA]()); err != nil {
+		t.Errorf("failed to register name id: %v", err)
+	}
+
+	if err := codec.RegisterName("b", reflect.TypeFor[EventB]()); err != nil {
+		t.Errorf("failed to register name: %v", err)
+	}
+
+	if err := codec.RegisterNameID(1, reflect.TypeFor[Struct1]()); !errors.Is(err, NameExists) {
+		t.Errorf("expected %v, received: %v", NameExists, err)
+	}
+
+	if err := codec.RegisterNameID(2, reflect.TypeFor[EventB]()); !errors.Is(err, kind.TypeExists) {
+		t.Errorf("expected %v, received: %v", kind.TypeExists, err)
+	}
+
+	data, err := codec.Marshal(codec.Interface(&EventA{ID: 1}))
+	if err != nil {
+		t.Error("failed to marshal name id")
+	}
+
+	if expected := []byte{kindNamedID, 1, 10, 1}; !slices.Equal(data, expected) {
+		t.Errorf("expected %v, received: %v", expected, data)
+	}
+
+	bus := &Bus{
+		Event:  &EventA{ID: 1},
+		Events: []interface{}{&EventA{ID: 2}, EventB{Name: "bin"}},
+		ByName: map[string]interface{}{"a": &EventA{ID: 3}},
+	}
+
+	if data, err = codec.Marshal(bus); err != nil {
+		t.Error("failed to marshal name id")
+	}
+
+	var st *Bus
+	if err = codec.Unmarshal(data, &st); err != nil {
+		t.Errorf("failed to unmarshal name id: %v", err)
+	}
+
+	if !reflect.DeepEqual(st, bus) {
+		t.Errorf("expected %v, received: %v", bus, st)
+	}
+
+	var unknown *Bus
+	if err = NewCodec().Unmarshal(data, &unknown); !errors.Is(err, UnknownName) {
+		t.Errorf("expected %v, received: %v", UnknownName, err)
+	}
+}
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 `bin:"10"`
+}
+
+func (circle *Circle) Area() float64 {
+	return 3 * circle.Radius * circle.Radius
+}
+
+type Square struct {
+	Side float64 `bin:"10"`
+}
+
+func (square Square) Area() float64 {
+	return square.Side * square.Side
+}
+
+type Drawing struct {
+	Main   Shape            `bin:"10"`
+	Shapes []Shape          `bin:"20"`
+	ByName map[string]Shape `bin:"30"`
+}
+
+func TestRegisterImpl(t *testing.T) {
+	codec := NewCodec()
+
+	if err := codec.RegisterImpl(1, reflect.TypeFor[Shape](), reflect.TypeFor[*Circle]()); err != nil {
+		t.Errorf("failed to register impl: %v", err)
+	}
+
+	if err := codec.RegisterImpl(2, reflect.TypeFor[Shape](), reflect.TypeFor[Square]()); err != nil {
+		t.Errorf("failed to register impl: %v", err)
+	}
+
+	if err := codec.RegisterImpl(3, reflect.TypeFor[Shape](), reflect.TypeFor[Struct1]()); !errors.Is(err, NotImplemented) {
+		t.Errorf("expected %v, received: %v", NotImplemented, err)
+	}
+
+	if err := codec.RegisterImpl(1, reflect.TypeFor[Shape](), reflect.TypeFor[*Square]()); !errors.Is(err, ImplExists) {
+		t.Errorf("expected %v, received: %v", ImplExists, err)
+	}
+
+	drawing := &Drawing{
+		Main:   &Circle{Radius: 1},
+		Shapes: []Shape{Square{Side: 2}, &Circle{Radius: 3}},
+		ByName: map[string]Shape{"square": Square{Side: 4}},
+	}
+
+	data, err := codec.Marshal(drawing)
+	if err != nil {
+		t.Error("failed to marshal impl")
+	}
+
+	var st *Drawing
+	if err = codec.Unmarshal(data, &st); err != nil {
+		t.Errorf("failed to unmarshal impl: %v", err)
+	}
+
+	if !reflect.DeepEqual(st, drawing) {
+		t.Errorf("expected %v, received: %v", drawing, st)
+	}
+
+	// A struct that is not an implementation can't be set into a Shape.
+	if data, err = codec.Marshal(&struct {
+		Main interface{} `bin:"10"`
+	}{Main: Struct2}); err != nil {
+		t.Error("failed to marshal struct")
+	}
+
+	if err = codec.Unmarshal(data, &st); !errors.Is(err, NotImplemented) {
+		t.Errorf("expected %v, received: %v", NotImplemented, err)
+	}
+}
+
+func TestStructBuilder(t *testing.T) {
+	s := NewStruct().Set(100, "one").Set(200, uint64(2)).Set(300, true).Delete(300)
+
+	if s.Len() != 2 || !reflect.DeepEqual(s.Tags(), []int{100, 200}) {
+		t.Errorf("expected %v, received: %v", []int{100, 200}, s.Tags())
+	}
+
+	data, err := Marshal(Interface(s))
+	if err != nil {
+		t.Error("failed to marshal struct builder")
+	}
+
+	expected, err := Marshal(Interface(&Struct1{FieldOne: "one", FieldTwo: 2}))
+	if err != nil {
+		t.Error("failed to marshal struct")
+	}
+
+	if string(data) != string(expected) {
+		t.Errorf("expected %v, received: %v", expected, data)
+	}
+
+	s.Set(300, NewStruct().Set(1, []int{1, 2}))
+
+	if data, err = Marshal(s); err != nil {
+		t.Error("failed to marshal struct builder")
+	}
+
+	st, err := Unmarshal[*Struct](data)
+	if err != nil {
+		t.Error("failed to unmarshal struct builder")
+	}
+
+	if v, _ := st.Get(100); v != "one" {
+		t.Errorf("expected %v, received: %v", "one", v)
+	}
+
+	v, _ := st.Get(300)
+	if v, _ := v.(*Struct).Get(1); !reflect.DeepEqual(v, []int{1, 2}) {
+		t.Errorf("expected %v, received: %v", []int{1, 2}, v)
+	}
+}
+
+func TestStructPath(t *testing.T) {
+	s := NewStruct().Set(20, NewStruct().Set(3, []interface{}{"zero", 1, NewStruct().Set(7, "seven")})).
+		Set(30, map[string]int{"bin": 42})
+
+	data, err := Marshal(s)
+	if err != nil {
+		t.Error("failed to marshal path")
+	}
+
+	st, err := Unmarshal[*Struct](data)
+	if err != nil {
+		t.Error("failed to unmarshal path")
+	}
+
+	for _, test := range []struct {
+		path     string
+		expected interface{}
+		ok       bool
+		err      error
+	}{
+		{"20.3.[2].7", "seven", true, nil},
+		{"20.3.[1]", 1, true, nil},
+		{"30.[bin]", 42, true, nil},
+		{"20.3.[3]", nil, false, nil},
+		{"20.4", nil, false, nil},
+		{"30.[go]", nil, false, nil},
+		{"20.3.7", nil, false, InvalidPath},
+		{"20.[x]", nil, false, InvalidPath},
+		{"20.3.[a]", nil, false, InvalidPath},
+	} {
+		v, ok, err := st.Lookup(test.path)
+
+		if v != test.expected || ok != test.ok || !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v %v %v, received: %v %v %v", test.path, test.expected, test.ok, test.err, v, ok, err)
+		}
+	}
+
+	if v := st.Path("20.3.[0]"); v != "zero" {
+		t.Errorf("expected %v, received: %v", "zero", v)
+	}
+}
+
+func TestStructIterator(t *testing.T) {
+	s := NewStruct().Set(30, "c").Set(10, "a").Set(20, "b")
+
+	var tags []int
+	var values []interface{}
+
+	for tag, v := range s.All() {
+		tags = append(tags, tag)
+		values = append(values, v)
+	}
+
+	if !reflect.DeepEqual(tags, []int{10, 20, 30}) || !reflect.DeepEqual(values, []interface{}{"a", "b", "c"}) {
+		t.Errorf("expected %v %v, received: %v %v", []int{10, 20, 30}, []string{"a", "b", "c"}, tags, values)
+	}
+}
+
+func TestStructWalk(t *testing.T) {
+	s := NewStruct().Set(10, "a").Set(20, NewStruct().Set(1, []int{5})).Set(30, map[string]int{"b": 2, "a": 1})
+
+	var paths []string
+
+	s.Walk(func(path []byte, v interface{}) bool {
+		paths = append(paths, string(path))
+
+		if lv := s.Path(string(path)); !reflect.DeepEqual(lv, v) {
+			t.Errorf("%s: expected %v, received: %v", path, v, lv)
+		}
+
+		return true
+	})
+
+	expected := []string{"10", "20", "20.1", "20.1.[0]", "30", "30.[a]", "30.[b]"}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, received: %v", expected, paths)
+	}
+
+	paths = nil
+
+	s.Walk(func(path []byte, v interface{}) bool {
+		paths = append(paths, string(path))
+		return string(path) != "20.1"
+	})
+
+	if !reflect.DeepEqual(paths, expected[:3]) {
+		t.Errorf("expected %v, received: %v", expected[:3], paths)
+	}
+
+	// Byte slices are a single value and nil pointers under slices are left as they are.
+	nested := []*Struct{nil, NewStruct().Set(1, "a")}
+	s = NewStruct().Set(10, []byte("bytes")).Set(20, nested).Set(30, map[int]string{10: "b", 9: "a"})
+
+	paths = nil
+
+	s.Walk(func(path []byte, v interface{}) bool {
+		paths = append(paths, string(path))
+		return true
+	})
+
+	if expected = []string{"10", "20", "20.[0]", "20.[1]", "20.[1].1", "30", "30.[10]", "30.[9]"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, received: %v", expected, paths)
+	}
+
+	if nested[0] != nil {
+		t.Errorf("expected %v, received: %v", nil, nested[0])
+	}
+
+	// Only boxing values for fn allocates, pointers don't need it so the walk costs the same for any length.
+	walk := func(n int) float64 {
+		x := 1
+		list := make([]*Struct, n)
+		m := make(map[int]*int, n)
+
+		for i := range list {
+			list[i] = NewStruct().Set(1, &x)
+			m[i] = &x
+		}
+
+		s := NewStruct().Set(10, list).Set(20, m)
+
+		return testing.AllocsPerRun(10, func() {
+			s.Walk(func(path []byte, v interface{}) bool {
+				return true
+			})
+		})
+	}
+
+	if one, many := walk(1), walk(100); many > one+20 {
+		t.Errorf("expected at most %v allocations, received: %v", one+20, many)
+	}
+}
+
+type StructStrict struct {
+	Small  int8      `bin:"1"`
+	Float  float64   `bin:"2"`
+	Wide   uint64    `bin:"3"`
+	Ints   []int     `bin:"4"`
+	Int    int       `bin:"5"`
+	Nested *Struct1  `bin:"6"`
+	Floats []float32 `bin:"7"`
+}
+
+func TestAsStrict(t *testing.T) {
+	data, err := Marshal(NewStruct().
+		Set(1, int64(300)).
+		Set(2, int64(5)).
+		Set(3, uint32(7)).
+		Set(4, []interface{}{1, "x"}).
+		Set(5, "five").
+		Set(6, NewStruct().Set(100, "one").Set(200, "two")).
+		Set(7, []float64{0.5, 0.1}))
+	if err != nil {
+		t.Error("failed to marshal strict")
+	}
+
+	s, err := Unmarshal[*Struct](data)
+	if err != nil {
+		t.Error("failed to unmarshal strict")
+	}
+
+	var st StructStrict
+	err = s.AsStrict(&st)
+
+	var paths []string
+
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var fe *FieldError
+		if !errors.As(err, &fe) {
+			t.Errorf("expected a field error, received: %v", err)
+			continue
+		}
+
+		paths = append(paths, fe.Path)
+	}
+
+	if expected := []string{"1", "4.[1]", "5", "6.200", "7.[1]"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, received: %v", expected, paths)
+	}
+
+	if !errors.Is(err, Overflow) || !errors.Is(err, Mismatch) {
+		t.Errorf("expected %v and %v, received: %v", Overflow, Mismatch, err)
+	}
+
+	expected := StructStrict{Float: 5, Wide: 7, Ints: []int{1, 0}, Nested: &Struct1{FieldOne: "one"}, Floats: []float32{0.5, 0}}
+
+	if !reflect.DeepEqual(st, expected) {
+		t.Errorf("expected %v, received: %v", expected, st)
+	}
+}
+
+func TestTryAs(t *testing.T) {
+	if v, err := TryAs[[]float64]([]interface{}{1, uint8(2), float32(2.5)}); err != nil || !reflect.DeepEqual(v, []float64{1, 2, 2.5}) {
+		t.Errorf("expected %v, received: %v %v", []float64{1, 2, 2.5}, v, err)
+	}
+
+	if _, err := TryAs[int8](int64(1000)); !errors.Is(err, Overflow) {
+		t.Errorf("expected %v, received: %v", Overflow, err)
+	}
+
+	if _, err := TryAs[uint](-1); !errors.Is(err, Overflow) {
+		t.Errorf("expected %v, received: %v", Overflow, err)
+	}
+
+	if v, err := TryAs[map[string]int64](map[interface{}]interface{}{"a": int8(1)}); err != nil || v["a"] != 1 {
+		t.Errorf("expected %v, received: %v %v", map[string]int64{"a": 1}, v, err)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	st, err := Unmarshal[*StructNumbers](expectedStructNumbers)
+	if err != nil {
+		t.Error("failed to unmarshal")
+	}
+
+	if !reflect.DeepEqual(st, StructNumbersValue) {
+		t.Errorf("expected %v, received: %v", StructNumbersValue, st)
+		return
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	data, err := Marshal(StructAllValue)
+	if err != nil {
+		b.Error("failed to encode (bench)")
+	}
+
+	b.StopTimer()
+
+	if string(data) != string(expectedStructAll) {
+		b.Error("not equal (bench)")
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	sa, err := Unmarshal[*StructAll](expectedStructAll)
+	if err != nil {
+		b.Errorf("failed to unmarshalas (bench): %v", err)
+	}
+
+	b.StopTimer()
+
+	if !reflect.DeepEqual(sa, StructAllValue) {
+		b.Error("not equal (bench)")
+	}
+}
+
+func BenchmarkEncodeBytes(b *testing.B) {
+	data := make([]byte, 1<<16)
+	encoder := NewEncoder(buffer.New())
+
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		encoder.writer = buffer.New()
+
+		if err := encoder.Encode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	data, err := Marshal(make([]byte, 1<<16))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		var out []byte
+
+		if err = NewDecoder(buffer.From(data)).Decode(&out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeInts(b *testing.B) {
+	data := make([]int64, 1<<12)
+	for i := range data {
+		data[i] = int64(i) << 20
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeInts(b *testing.B) {
+	in := make([]int64, 1<<12)
+	for i := range in {
+		in[i] = int64(i) << 20
+	}
+
+	data, err := Marshal(in)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err = Unmarshal[[]int64](data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeFloats(b *testing.B) {
+	data := make([]float64, 1<<12)
+	for i := range data {
+		data[i] = float64(i) / 3
+	}
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type Document struct {
+	Title string            `bin:"1"`
+	Tags  []string          `bin:"2"`
+	Meta  map[string]uint64 `bin:"3"`
+	Note  string            `bin:"4"`
+}
+
+func TestDiff(t *testing.T) {
+	a := &Document{Title: "draft", Tags: []string{"a", "b"}, Meta: map[string]uint64{"views": 1, "old": 2}, Note: "note"}
+	b := &Document{Title: "final", Tags: []string{"a", "c", "d"}, Meta: map[string]uint64{"views": 5, "new": 3}}
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+
+	data, err := Marshal(changes)
+	if err != nil {
+		t.Fatalf("failed to marshal changes: %v", err)
+	}
+
+	changes, err = Unmarshal[[]Change](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal changes: %v", err)
+	}
+
+	if err = Patch(a, changes); err != nil {
+		t.Fatalf("failed to patch: %v", err)
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected %+v, received: %+v", b, a)
+	}
+
+	if changes, _ = Diff(a, b); len(changes) != 0 {
+		t.Errorf("expected no changes, received: %v", changes)
+	}
+
+	if _, err = Diff(a, *b); !errors.Is(err, Mismatch) {
+		t.Errorf("expected %v, received: %v", Mismatch, err)
+	}
+}
+
+func TestDiffStruct(t *testing.T) {
+	a := NewStruct().Set(1, "a").Set(2, []interface{}{uint64(1), uint64(2)}).Set(3, NewStruct().Set(1, true))
+	b := NewStruct().Set(1, "b").Set(2, []interface{}{uint64(1), uint64(3)}).Set(3, NewStruct())
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+
+	if err = Patch(a, changes); err != nil {
+		t.Fatalf("failed to patch: %v", err)
+	}
+
+	if !reflect.DeepEqual(a.Map(), b.Map()) {
+		t.Errorf("expected %v, received: %v", b.Map(), a.Map())
+	}
+
+	if err = Patch(a, []Change{{Op: ChangeSet, Path: "9.[0]", Value: 1}}); !errors.Is(err, InvalidPath) {
+		t.Errorf("expected %v, received: %v", InvalidPath, err)
+	}
+}
+
+type opaque struct {
+	n int
+}
+
+type DiffKinds struct {
+	Temp   Celsius `bin:"1"`
+	Opaque opaque  `bin:"2"`
+}
+
+func TestDiffKinds(t *testing.T) {
+	a := &DiffKinds{Temp: Celsius{Degrees: 1}, Opaque: opaque{n: 1}}
+	b := &DiffKinds{Temp: Celsius{Degrees: 2}, Opaque: opaque{n: 2}}
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+
+	if expected := []Change{{Op: ChangeSet, Path: "1.1", Value: 2}, {Op: ChangeSet, Path: "2", Value: opaque{n: 2}}}; !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v, received: %v", expected, changes)
+	}
+
+	codec := NewCodec()
+
+	if err = codec.Register(200, reflect.TypeFor[Celsius](), celsius(0)); err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	if changes, err = codec.Diff(a, b); err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+
+	if expected := []Change{{Op: ChangeSet, Path: "1", Value: Celsius{Degrees: 2}}, {Op: ChangeSet, Path: "2", Value: opaque{n: 2}}}; !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v, received: %v", expected, changes)
+	}
+}
+
+type Record struct {
+	ID    uint64       `bin:"1"`
+	Body  string       `bin:"2"`
+	Owner *RecordOwner `bin:"3"`
+	Items []RecordItem `bin:"4"`
+}
+
+type RecordOwner struct {
+	Name  string `bin:"1"`
+	Email string `bin:"2"`
+}
+
+type RecordItem struct {
+	Name  string `bin:"1"`
+	Count uint64 `bin:"2"`
+}
+
+var RecordValue = &Record{
+	ID:    7,
+	Body:  "a big body",
+	Owner: &RecordOwner{Name: "Gopher", Email: "gopher@example.com"},
+	Items: []RecordItem{{Name: "a", Count: 1}, {Name: "b", Count: 2}},
+}
+
+func TestMarshalMask(t *testing.T) {
+	mask, err := NewFieldMask("1", "3.1", "4.2")
+	if err != nil {
+		t.Fatalf("failed to make mask: %v", err)
+	}
+
+	expected := &Record{
+		ID:    7,
+		Owner: &RecordOwner{Name: "Gopher"},
+		Items: []RecordItem{{Count: 1}, {Count: 2}},
+	}
+
+	data, err := MarshalMask(RecordValue, mask)
+	if err != nil {
+		t.Fatalf("failed to marshal mask: %v", err)
+	}
+
+	r, err := Unmarshal[*Record](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal mask: %v", err)
+	}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, received: %+v", expected, r)
+	}
+
+	if full, _ := Marshal(RecordValue); len(data) >= len(full) {
+		t.Errorf("expected less than %d bytes, received: %d", len(full), len(data))
+	}
+
+	data, err = MarshalMask(Interface(RecordValue), mask)
+	if err != nil {
+		t.Fatalf("failed to marshal mask interface: %v", err)
+	}
+
+	i, err := Unmarshal[interface{}](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal mask interface: %v", err)
+	}
+
+	if tags := i.(*Struct).Tags(); !slices.Equal(tags, []int{1, 3, 4}) {
+		t.Errorf("expected %v, received: %v", []int{1, 3, 4}, tags)
+	}
+
+	if _, err = NewFieldMask("1.x"); !errors.Is(err, InvalidPath) {
+		t.Errorf("expected %v, received: %v", InvalidPath, err)
+	}
+}
+
+func TestMarshalMaskStruct(t *testing.T) {
+	mask, _ := NewFieldMask("1", "2", "4")
+
+	data, err := MarshalMask(RecordValue, mask)
+	if err != nil {
+		t.Fatalf("failed to marshal mask: %v", err)
+	}
+
+	r, err := Unmarshal[*Record](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal mask: %v", err)
+	}
+
+	expected := &Record{ID: RecordValue.ID, Body: RecordValue.Body, Items: RecordValue.Items}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, received: %+v", expected, r)
+	}
+}
+
+type NilStruct struct {
+	Owner *RecordOwner `bin:"1"`
+	Text  string       `bin:"2"`
+	Count uint64       `bin:"3"`
+}
+
+func TestNilStruct(t *testing.T) {
+	for _, ns := range []*NilStruct{{Text: "text", Count: 5}, {Owner: &RecordOwner{}, Text: "text"}} {
+		data, err := Marshal(ns)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		received, err := Unmarshal[*NilStruct](data)
+		if err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+
+		if !reflect.DeepEqual(received, ns) {
+			t.Errorf("expected %+v, received: %+v", ns, received)
+		}
+	}
+}
+
+func TestMasked(t *testing.T) {
+	mask, _ := NewFieldMask("2", "3.2", "3")
+
+	data, err := Marshal(RecordValue)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var r Record
+	if err = NewDecoder(buffer.From(data), Masked(mask)).Decode(&r); err != nil {
+		t.Fatalf("failed to decode masked: %v", err)
+	}
+
+	expected := Record{Body: RecordValue.Body, Owner: RecordValue.Owner}
+
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected %+v, received: %+v", expected, r)
+	}
+
+	data, err = Marshal(Interface(RecordValue))
+	if err != nil {
+		t.Fatalf("failed to marshal interface: %v", err)
+	}
+
+	var i interface{}
+	if err = NewDecoder(buffer.From(data), Masked(FieldMask{4: {1: nil}})).Decode(&i); err != nil {
+		t.Fatalf("failed to decode masked interface: %v", err)
+	}
+
+	if v := i.(*Struct).Path("4.[1].1"); v != "b" {
+		t.Errorf("expected %v, received: %v", "b", v)
+	}
+
+	if v := i.(*Struct).Path("4.[1].2"); v != nil {
+		t.Errorf("expected %v, received: %v", nil, v)
+	}
+}
+
+type MessageV1 struct {
+	ID      uint64  `bin:"1"`
+	Unknown Unknown `bin:"-"`
+}
+
+type MessageV2 struct {
+	ID      uint64   `bin:"1"`
+	Name    string   `bin:"2"`
+	Tags    []string `bin:"3"`
+	Unknown Unknown  `bin:"-"`
+}
+
+func TestUnknown(t *testing.T) {
+	v2 := []MessageV2{{ID: 1, Name: "one", Tags: []string{"a"}}, {ID: 2, Name: "two", Tags: []string{"b"}}}
+
+	data, err := Marshal(v2)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	v1, err := Unmarshal[[]MessageV1](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal older: %v", err)
+	}
+
+	if len(v1) != 2 || v1[0].ID != 1 || v1[1].ID != 2 {
+		t.Fatalf("expected ids 1 and 2, received: %+v", v1)
+	}
+
+	if tags := v1[0].Unknown.Tags(); !slices.Equal(tags, []int{2, 3}) {
+		t.Errorf("expected %v, received: %v", []int{2, 3}, tags)
+	}
+
+	v1[1].ID = 3
+
+	if data, err = Marshal(v1); err != nil {
+		t.Fatalf("failed to marshal older: %v", err)
+	}
+
+	received, err := Unmarshal[[]MessageV2](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	v2[1].ID = 3
+
+	if !reflect.DeepEqual(received, v2) {
+		t.Errorf("expected %+v, received: %+v", v2, received)
+	}
+}
+
+type MessageTagged struct {
+	ID      uint64  `bin:"1"`
+	Unknown Unknown `bin:"9"`
+}
+
+func TestUnknownInvalid(t *testing.T) {
+	// Written before MessageV1 had an Unknown field.
+	data, err := Marshal(&struct {
+		ID   uint64 `bin:"1"`
+		Name string `bin:"2"`
+	}{ID: 1, Name: "x"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	if _, err = Unmarshal[*MessageV1](data); !errors.Is(err, NotFramed) {
+		t.Errorf("expected %v, received: %v", NotFramed, err)
+	}
+
+	for _, c := range []struct {
+		data []byte
+		err  error
+	}{
+		{[]byte{128, 0, 1, 1, 255, 255, 255, 255, 255, 255, 255, 255, 255, 1}, Invalid},
+		{[]byte{128, 0, 1, 1, 128, 128, 128, 128, 8}, Invalid},
+		{[]byte{128, 0, 1, 1, 5, 1}, io.ErrUnexpectedEOF},
+	} {
+		if _, err = Unmarshal[*MessageV1](c.data); !errors.Is(err, c.err) {
+			t.Errorf("expected %v, received: %v", c.err, err)
+		}
+	}
+
+	// Interfaced structs don't write the Unknown field, so it isn't counted either.
+	if data, err = Marshal(Interface(&MessageTagged{ID: 1, Unknown: Unknown{5: {1}}})); err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	i, err := Unmarshal[interface{}](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if n := i.(*Struct).Len(); n != 1 {
+		t.Errorf("expected %v, received: %v", 1, n)
+	}
+}
+
+type Envelope struct {
+	ID   uint64        `bin:"1"`
+	Ping *EnvelopePing `bin:"2,oneof=payload"`
+	Text string        `bin:"3,oneof=payload"`
+	Code *uint64       `bin:"4,oneof=payload"`
+	Note *string       `bin:"5,oneof=payload"`
+}
+
+type EnvelopePing struct {
+	Seq uint64 `bin:"1"`
+}
+
+func TestOneOf(t *testing.T) {
+	code, zero, empty := uint64(5), uint64(0), ""
+
+	for _, e := range []*Envelope{
+		{ID: 1, Ping: &EnvelopePing{Seq: 2}}, {ID: 2, Ping: &EnvelopePing{}}, {ID: 3, Text: "text"},
+		{ID: 4, Code: &code}, {ID: 5, Code: &zero}, {ID: 6, Note: &empty}, {ID: 7},
+	} {
+		data, err := Marshal(e)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+
+		received, err := Unmarshal[*Envelope](data)
+		if err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+
+		if !reflect.DeepEqual(received, e) {
+			t.Errorf("expected %+v, received: %+v", e, received)
+		}
+
+		tag, v := OneOf(received, "payload")
+		if expected, _ := OneOf(e, "payload"); tag != expected || (tag == 0) != (v == nil) {
+			t.Errorf("expected tag %d, received: %d %v", expected, tag, v)
+		}
+	}
+
+	conflict := &Envelope{Ping: &EnvelopePing{}, Text: "text"}
+
+	if _, err := Marshal(conflict); !errors.Is(err, OneOfConflict) {
+		t.Errorf("expected %v, received: %v", OneOfConflict, err)
+	}
+
+	if _, err := Marshal(Interface(conflict)); !errors.Is(err, OneOfConflict) {
+		t.Errorf("expected %v, received: %v", OneOfConflict, err)
+	}
+
+	if data, _ := Marshal(&Envelope{Code: &zero}); !slices.Equal(data, []byte{1, 0, 2, 0, 3, 0, 4, 1, 0, 5, 0}) {
+		t.Errorf("expected %v, received: %v", []byte{1, 0, 2, 0, 3, 0, 4, 1, 0, 5, 0}, data)
+	}
+
+	// Written by hand as an encoder never writes both.
+	if _, err := Unmarshal[*Envelope]([]byte{1, 0, 2, 1, 1, 2, 3, 1, 120, 4, 0, 5, 0}); !errors.Is(err, OneOfConflict) {
+		t.Errorf("expected %v, received: %v", OneOfConflict, err)
+	}
+}
+
+type Status uint16
+
+const (
+	StatusOK Status = iota + 1
+	StatusNotFound
+)
+
+type Reply struct {
+	Status Status `bin:"1"`
+}
+
+type Replies struct {
+	Statuses []Status `bin:"1"`
+	Reply    *Reply   `bin:"2"`
+}
+
+func statusCodec(t *testing.T) *Codec {
+	codec := NewCodec()
+
+	if err := codec.RegisterEnum(reflect.TypeFor[Status](), map[int64]string{1: "ok", 2: "not_found"}); err != nil {
+		t.Fatalf("failed to register enum: %v", err)
+	}
+
+	return codec
+}
+
+func TestEnum(t *testing.T) {
+	codec := statusCodec(t)
+
+	data, err := codec.Marshal(&Reply{Status: StatusNotFound})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	if expected, _ := Marshal(&struct {
+		Status uint16 `bin:"1"`
+	}{Status: 2}); !slices.Equal(data, expected) {
+		t.Errorf("expected %v, received: %v", expected, data)
+	}
+
+	if name, ok := codec.EnumName(StatusNotFound); !ok || name != "not_found" {
+		t.Errorf("expected %v, received: %v %v", "not_found", name, ok)
+	}
+
+	if _, ok := codec.EnumName(Status(9)); ok {
+		t.Errorf("expected %v to be undefined", Status(9))
+	}
+
+	// Other codecs don't know the names.
+	if _, ok := EnumName(StatusOK); ok {
+		t.Errorf("expected %v to be unnamed", StatusOK)
+	}
+
+	if err = codec.RegisterEnum(reflect.TypeFor[Status](), nil); !errors.Is(err, EnumExists) {
+		t.Errorf("expected %v, received: %v", EnumExists, err)
+	}
+
+	if err = codec.RegisterEnum(reflect.TypeFor[string](), nil); !errors.Is(err, Invalid) {
+		t.Errorf("expected %v, received: %v", Invalid, err)
+	}
+
+	if data, err = codec.Marshal(&Reply{Status: 9}); err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var r Reply
+	if err = codec.NewDecoder(buffer.From(data), StrictEnums()).Decode(&r); !errors.Is(err, UnknownEnum) {
+		t.Errorf("expected %v, received: %v", UnknownEnum, err)
+	}
+
+	if err = codec.NewDecoder(buffer.From(data)).Decode(&r); err != nil || r.Status != 9 {
+		t.Errorf("expected %v, received: %v %v", Status(9), r.Status, err)
+	}
+
+	if data, err = codec.Marshal([]Status{StatusOK, 9}); err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var s []Status
+	if err = codec.NewDecoder(buffer.From(data), StrictEnums()).Decode(&s); !errors.Is(err, UnknownEnum) {
+		t.Errorf("expected %v, received: %v", UnknownEnum, err)
+	}
+}
+
+func TestEnumInterface(t *testing.T) {
+	codec := statusCodec(t)
+
+	replies := &Replies{Statuses: []Status{StatusOK, 9}, Reply: &Reply{Status: StatusNotFound}}
+
+	data, err := codec.Marshal(codec.Interface(replies))
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	// The names don't change what is written.
+	if expected, _ := Marshal(Interface(&struct {
+		Statuses []uint16 `bin:"1"`
+		Reply    *struct {
+			Status uint16 `bin:"1"`
+		} `bin:"2"`
+	}{Statuses: []uint16{1, 9}, Reply: &struct {
+		Status uint16 `bin:"1"`
+	}{Status: 2}})); !slices.Equal(data, expected) {
+		t.Errorf("expected %v, received: %v", expected, data)
+	}
+
+	var i interface{}
+	if err = codec.Unmarshal(data, &i); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if m := i.(*Struct).Map(); m[2].(map[interface{}]interface{})[1] != uint16(StatusNotFound) {
+		t.Errorf("expected %v, received: %v", uint16(StatusNotFound), m)
+	}
+
+	m := i.(*Struct).MapOf(reflect.TypeFor[*Replies]())
+
+	if expected := []interface{}{"ok", Status(9)}; !reflect.DeepEqual(m[1], expected) {
+		t.Errorf("expected %v, received: %v", expected, m[1])
+	}
+
+	if expected := map[interface{}]interface{}{1: "not_found"}; !reflect.DeepEqual(m[2], expected) {
+		t.Errorf("expected %v, received: %v", expected, m[2])
 	}
 }
@@ -0,0 +1,114 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+//go:build !dviih_bin_kind_time
+
+package bin
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type StructTime struct {
+	Time     time.Time     `bin:"10"`
+	Duration time.Duration `bin:"20"`
+	Offset   time.Time     `bin:"30"`
+	Zero     time.Time     `bin:"40"`
+}
+
+var StructTimeValue = &StructTime{
+	Time:     time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC),
+	Duration: -90 * time.Second,
+	Offset:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)),
+}
+
+func TestTime(t *testing.T) {
+	data, err := Marshal(StructTimeValue)
+	if err != nil {
+		t.Error("failed to marshal time")
+	}
+
+	st, err := Unmarshal[*StructTime](data)
+	if err != nil {
+		t.Error("failed to unmarshal time")
+	}
+
+	if !reflect.DeepEqual(st, StructTimeValue) {
+		t.Errorf("expected %v, received: %v", StructTimeValue, st)
+	}
+}
+
+func TestTimeInterface(t *testing.T) {
+	data, err := Marshal(Interface(StructTimeValue))
+	if err != nil {
+		t.Error("failed to marshal time interface")
+	}
+
+	i, err := Unmarshal[interface{}](data)
+	if err != nil {
+		t.Error("failed to unmarshal time interface")
+	}
+
+	if v, _ := i.(*Struct).Get(10); v != StructTimeValue.Time {
+		t.Errorf("expected %v, received: %v", StructTimeValue.Time, v)
+	}
+
+	if v, _ := i.(*Struct).Get(20); v != StructTimeValue.Duration {
+		t.Errorf("expected %v, received: %v", StructTimeValue.Duration, v)
+	}
+}
+
+func TestTimeLegacy(t *testing.T) {
+	tm := time.Date(2025, 1, 2, 3, 4, 5, 6, time.FixedZone("", 3600))
+
+	blob, err := tm.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal binary: %v", err)
+	}
+
+	// Written when time.Time was kind 65.
+	data, err := Marshal(&struct {
+		Time []byte `bin:"10"`
+	}{Time: blob})
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	st, err := Unmarshal[*struct {
+		Time time.Time `bin:"10"`
+	}](data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+
+	if !st.Time.Equal(tm) || st.Time.String() != tm.String() {
+		t.Errorf("expected %v, received: %v", tm, st.Time)
+	}
+
+	if data, err = Marshal(make([]byte, timeBinary+1)); err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	if _, err = Unmarshal[time.Time](data); !errors.Is(err, Invalid) {
+		t.Errorf("expected %v, received: %v", Invalid, err)
+	}
+}
@@ -40,9 +40,13 @@ var (
 	InvalidWhence = errors.New("invalid whence")
 )
 
+// Write writes what fits under Max, returning io.ErrShortWrite if data doesn't fit.
 func (buffer *Buffer) Write(data []byte) (int, error) {
 	if len(buffer.data)+len(data) > buffer.Max {
-		data = data[:buffer.Max-len(buffer.data)]
+		n := max(buffer.Max-len(buffer.data), 0)
+		buffer.data = append(buffer.data, data[:n]...)
+
+		return n, io.ErrShortWrite
 	}
 
 	buffer.data = append(buffer.data, data...)
@@ -50,6 +54,29 @@ func (buffer *Buffer) Write(data []byte) (int, error) {
 	return len(data), nil
 }
 
+func (buffer *Buffer) WriteByte(b byte) error {
+	if len(buffer.data) >= buffer.Max {
+		return io.ErrShortWrite
+	}
+
+	buffer.data = append(buffer.data, b)
+	return nil
+}
+
+// WriteString is the same as Write for strings.
+func (buffer *Buffer) WriteString(s string) (int, error) {
+	if len(buffer.data)+len(s) > buffer.Max {
+		n := max(buffer.Max-len(buffer.data), 0)
+		buffer.data = append(buffer.data, s[:n]...)
+
+		return n, io.ErrShortWrite
+	}
+
+	buffer.data = append(buffer.data, s...)
+
+	return len(s), nil
+}
+
 func (buffer *Buffer) Read(data []byte) (int, error) {
 	if buffer.read >= int64(len(buffer.data)) {
 		return 0, io.EOF
@@ -119,6 +146,12 @@ func (buffer *Buffer) Slice(start, end int) *Buffer {
 	}
 }
 
+// Reset makes the buffer use data, reading starts over and writing appends to it.
+func (buffer *Buffer) Reset(data []byte) {
+	buffer.data = data
+	buffer.read = 0
+}
+
 func New() *Buffer {
 	return &Buffer{
 		Max: MaxSize,
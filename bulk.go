@@ -0,0 +1,178 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"github.com/Dviih/bin/kind"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Bulk paths write and read whole arrays and slices of bytes and numbers at once,
+// the output is the same as encoding every element on its own.
+
+// bulkSize is how many bytes of VarInts are batched before writing.
+const bulkSize = 4096
+
+func bulkable(kinds *kind.Map, t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+	default:
+		return false
+	}
+
+	return !registered(kinds, t)
+}
+
+func bytesOf(value reflect.Value) []byte {
+	if value.Kind() == reflect.Array && !value.CanAddr() {
+		ptr := reflect.New(value.Type()).Elem()
+		ptr.Set(value)
+
+		value = ptr
+	}
+
+	return value.Bytes()
+}
+
+func (encoder *Encoder) bulk(value reflect.Value) (bool, error) {
+	if found, err := encoder.packed(value); found {
+		return true, err
+	}
+
+	if found, err := encoder.delta(value); found {
+		return true, err
+	}
+
+	elem := value.Type().Elem()
+
+	if !bulkable(encoder.codec.kinds, elem) {
+		return false, nil
+	}
+
+	if elem.Kind() == reflect.Uint8 {
+		_, err := encoder.writer.Write(bytesOf(value))
+		return true, err
+	}
+
+	b := encoder.scratch[:0]
+
+	for i := 0; i < value.Len(); i++ {
+		if len(b) >= bulkSize {
+			if _, err := encoder.writer.Write(b); err != nil {
+				return true, err
+			}
+
+			b = b[:0]
+		}
+
+		element := value.Index(i)
+
+		switch elem.Kind() {
+		case reflect.Int8:
+			b = append(b, byte(element.Int()))
+		case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+			b = appendVarInt(b, element.Int())
+		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b = appendVarInt(b, element.Uint())
+		case reflect.Float32:
+			b = encoder.append32(b, math.Float32bits(float32(element.Float())))
+		case reflect.Float64:
+			b = encoder.append64(b, math.Float64bits(element.Float()))
+		case reflect.Complex64:
+			c := complex64(element.Complex())
+
+			b = encoder.append32(b, math.Float32bits(real(c)))
+			b = encoder.append32(b, math.Float32bits(imag(c)))
+		case reflect.Complex128:
+			c := element.Complex()
+
+			b = encoder.append64(b, math.Float64bits(real(c)))
+			b = encoder.append64(b, math.Float64bits(imag(c)))
+		}
+	}
+
+	encoder.scratch = b[:0]
+
+	_, err := encoder.writer.Write(b)
+	return true, err
+}
+
+func (decoder *Decoder) bulk(value reflect.Value) (bool, error) {
+	if found, err := decoder.packed(value); found {
+		return true, err
+	}
+
+	if found, err := decoder.delta(value); found {
+		return true, err
+	}
+
+	elem := value.Type().Elem()
+
+	if !bulkable(decoder.codec.kinds, elem) {
+		return false, nil
+	}
+
+	switch elem.Kind() {
+	case reflect.Uint8:
+		_, err := io.ReadFull(decoder.reader, value.Bytes())
+		return true, err
+	case reflect.Int8:
+		b := make([]byte, value.Len())
+
+		n, err := io.ReadFull(decoder.reader, b)
+
+		for i := 0; i < n; i++ {
+			value.Index(i).SetInt(int64(b[i]))
+		}
+
+		return true, err
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		element := value.Index(i)
+
+		switch elem.Kind() {
+		case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := VarIntOut[int64](decoder.reader)
+			if err != nil {
+				return true, err
+			}
+
+			element.SetInt(n)
+		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := VarIntOut[uint64](decoder.reader)
+			if err != nil {
+				return true, err
+			}
+
+			element.SetUint(n)
+		case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+			if err := decoder.float(element); err != nil {
+				return true, err
+			}
+		}
+	}
+
+	return true, nil
+}
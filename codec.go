@@ -0,0 +1,163 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"github.com/Dviih/bin/buffer"
+	"github.com/Dviih/bin/kind"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Codec owns its kinds and options, kinds registered to it don't leak to other codecs.
+type Codec struct {
+	kinds   *kind.Map
+	names   registry
+	impls   registry
+	enums   sync.Map
+	options options
+}
+
+// std is the codec behind the package functions.
+var std = &Codec{
+	kinds: mkind,
+}
+
+var (
+	encoders = sync.Pool{
+		New: func() interface{} {
+			return &Encoder{
+				writer: buffer.New(),
+			}
+		},
+	}
+
+	decoders = sync.Pool{
+		New: func() interface{} {
+			return &Decoder{
+				reader: buffer.New(),
+			}
+		},
+	}
+)
+
+func (codec *Codec) Register(n int, t reflect.Type, handler kind.Handler) error {
+	if n < 128 {
+		return InvalidKind
+	}
+
+	return codec.kinds.Store(n, Abs[reflect.Type](t), handler)
+}
+
+func (codec *Codec) Alias(n int, t reflect.Type) error {
+	if n < 128 {
+		return InvalidKind
+	}
+
+	return codec.kinds.Alias(n, Abs[reflect.Type](t))
+}
+
+func (codec *Codec) Unregister(n int) error {
+	return codec.kinds.Unregister(n)
+}
+
+func (codec *Codec) Registered() []kind.Data {
+	return codec.kinds.Registered()
+}
+
+// NewEncoder returns an Encoder using the kinds of the codec, opts are added to the options of the codec.
+func (codec *Codec) NewEncoder(writer io.Writer, opts ...Option) *Encoder {
+	return &Encoder{
+		writer:  writer,
+		options: codec.options.apply(opts),
+		codec:   codec,
+	}
+}
+
+// NewDecoder returns a Decoder using the kinds of the codec, opts are added to the options of the codec.
+func (codec *Codec) NewDecoder(reader io.Reader, opts ...Option) *Decoder {
+	return &Decoder{
+		reader:  reader,
+		options: codec.options.apply(opts),
+		codec:   codec,
+	}
+}
+
+func (codec *Codec) Marshal(v interface{}) ([]byte, error) {
+	return codec.MarshalAppend(nil, v)
+}
+
+// MarshalAppend encodes v appending to dst, when dst has enough capacity nothing is allocated.
+func (codec *Codec) MarshalAppend(dst []byte, v interface{}) ([]byte, error) {
+	return codec.marshal(dst, v, codec.options)
+}
+
+// MarshalMask encodes only the fields of mask.
+func (codec *Codec) MarshalMask(v interface{}, mask FieldMask) ([]byte, error) {
+	options := codec.options
+	options.mask = mask
+
+	return codec.marshal(nil, v, options)
+}
+
+func (codec *Codec) marshal(dst []byte, v interface{}, options options) ([]byte, error) {
+	encoder := encoders.Get().(*Encoder)
+	b := encoder.writer.(*buffer.Buffer)
+
+	b.Reset(dst)
+	encoder.options = options
+	encoder.codec = codec
+
+	defer func() {
+		b.Reset(nil)
+		encoders.Put(encoder)
+	}()
+
+	if err := encoder.Encode(v); err != nil {
+		return dst, err
+	}
+
+	return b.Data(), nil
+}
+
+func (codec *Codec) Unmarshal(data []byte, v interface{}) error {
+	decoder := decoders.Get().(*Decoder)
+	b := decoder.reader.(*buffer.Buffer)
+
+	b.Reset(data)
+	decoder.options = codec.options
+	decoder.codec = codec
+
+	defer func() {
+		b.Reset(nil)
+		decoders.Put(decoder)
+	}()
+
+	return decoder.Decode(v)
+}
+
+// NewCodec returns a codec starting with the kinds of this package, registered ones aren't included.
+func NewCodec(opts ...Option) *Codec {
+	return &Codec{
+		kinds:   builtins.Clone(),
+		options: options{}.apply(opts),
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// readDeadline and writeDeadline are implemented by readers and writers such as net.Conn and os.File.
+type readDeadline interface {
+	SetReadDeadline(time.Time) error
+}
+
+type writeDeadline interface {
+	SetWriteDeadline(time.Time) error
+}
+
+// EncodeContext is Encode stopping between elements and fields once ctx is done, returning ctx.Err().
+func (encoder *Encoder) EncodeContext(ctx context.Context, v interface{}) error {
+	encoder.ctx = ctx
+	defer func() {
+		encoder.ctx = nil
+	}()
+
+	var set func(time.Time) error
+	if d, ok := encoder.writer.(writeDeadline); ok {
+		set = d.SetWriteDeadline
+	}
+
+	stop, watched := watch(ctx, set)
+	defer stop()
+
+	if err := encoder.Encode(v); err != nil {
+		return contextErr(ctx, err, watched)
+	}
+
+	return nil
+}
+
+// DecodeContext is Decode stopping between elements and fields once ctx is done, returning ctx.Err().
+func (decoder *Decoder) DecodeContext(ctx context.Context, v interface{}) error {
+	decoder.ctx = ctx
+	defer func() {
+		decoder.ctx = nil
+	}()
+
+	var set func(time.Time) error
+	if d, ok := decoder.reader.(readDeadline); ok {
+		set = d.SetReadDeadline
+	}
+
+	stop, watched := watch(ctx, set)
+	defer stop()
+
+	if err := decoder.Decode(v); err != nil {
+		return contextErr(ctx, err, watched)
+	}
+
+	return nil
+}
+
+func (encoder *Encoder) done() error {
+	if encoder.ctx == nil {
+		return nil
+	}
+
+	return encoder.ctx.Err()
+}
+
+func (decoder *Decoder) done() error {
+	if decoder.ctx == nil {
+		return nil
+	}
+
+	return decoder.ctx.Err()
+}
+
+// contextErr returns ctx.Err() for errors caused by ctx, a deadline set by watch
+// may expire right before ctx does, so it counts as ctx once it has passed.
+func contextErr(ctx context.Context, err error, watched bool) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if t, ok := ctx.Deadline(); ok && watched && errors.Is(err, os.ErrDeadlineExceeded) && !time.Now().Before(t) {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
+
+// watch sets the deadline of ctx with set and expires it once ctx is done, so a blocked read or write returns,
+// reporting if the deadline of ctx was set. The returned function clears a deadline it set, without a deadline
+// or cancellation a deadline set by the caller is left as is.
+func watch(ctx context.Context, set func(time.Time) error) (func(), bool) {
+	if set == nil {
+		return func() {}, false
+	}
+
+	changed := false
+
+	t, watched := ctx.Deadline()
+	if watched {
+		watched = set(t) == nil
+		changed = true
+	}
+
+	expired := make(chan struct{})
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = set(time.Now())
+		close(expired)
+	})
+
+	return func() {
+		if !stop() {
+			<-expired
+			changed = true
+		}
+
+		if changed {
+			_ = set(time.Time{})
+		}
+	}, watched
+}
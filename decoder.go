@@ -20,27 +20,34 @@
 package bin
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"reflect"
 )
 
 type Decoder struct {
-	reader io.Reader
+	reader  io.Reader
+	options options
+	codec   *Codec
+	ctx     context.Context
 }
 
 func (decoder *Decoder) Decode(v interface{}) error {
-	value := Value(v)
+	return decoder.decode(Value(v))
+}
 
+func (decoder *Decoder) decode(value reflect.Value) error {
 	if !value.CanSet() {
 		return CantSet
 	}
 
-	if value.Type() == reflect.TypeFor[*Struct]() {
+	if value.Type() == reflect.TypeFor[*Struct]() || value.Type() == reflect.TypeFor[Struct]() {
 		Zero(value)
 		return decoder.structs(value)
 	}
 
-	found, err := mkind.Run(value.Type(), decoder, value)
+	found, err := decoder.codec.kinds.Run(value.Type(), decoder, value)
 	if err != nil {
 		return err
 	}
@@ -49,28 +56,17 @@ func (decoder *Decoder) Decode(v interface{}) error {
 		return nil
 	}
 
-	if v == nil {
-		value.SetZero()
-		return nil
-	}
-
 	switch value.Kind() {
 	case reflect.Invalid, reflect.Uintptr, reflect.UnsafePointer:
 		value.SetZero()
 		return nil
 	case reflect.Bool:
-		b := [1]byte{}
-
-		n, err := decoder.reader.Read(b[:])
+		b, err := readByte(decoder.reader)
 		if err != nil {
 			return err
 		}
 
-		if n != 1 {
-			return io.EOF
-		}
-
-		if b[0] == 255 {
+		if b == 255 {
 			value.Set(reflect.ValueOf(true))
 			return nil
 		}
@@ -78,19 +74,13 @@ func (decoder *Decoder) Decode(v interface{}) error {
 		value.Set(reflect.ValueOf(false))
 		return nil
 	case reflect.Int8:
-		b := [1]byte{}
-
-		n, err := decoder.reader.Read(b[:])
+		b, err := readByte(decoder.reader)
 		if err != nil {
 			return err
 		}
 
-		if n != 1 {
-			return io.EOF
-		}
-
-		value.SetInt(int64(b[0]))
-		return nil
+		value.SetInt(int64(b))
+		return decoder.defined(value)
 	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
 		n, err := VarIntOut[int64](decoder.reader)
 		if err != nil {
@@ -98,21 +88,15 @@ func (decoder *Decoder) Decode(v interface{}) error {
 		}
 
 		value.SetInt(n)
-		return nil
+		return decoder.defined(value)
 	case reflect.Uint8:
-		b := [1]byte{}
-
-		n, err := decoder.reader.Read(b[:])
+		b, err := readByte(decoder.reader)
 		if err != nil {
 			return err
 		}
 
-		if n != 1 {
-			return io.EOF
-		}
-
-		value.SetUint(uint64(b[0]))
-		return nil
+		value.SetUint(uint64(b))
+		return decoder.defined(value)
 	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		n, err := VarIntOut[uint64](decoder.reader)
 		if err != nil {
@@ -120,64 +104,47 @@ func (decoder *Decoder) Decode(v interface{}) error {
 		}
 
 		value.SetUint(n)
-		return nil
-	case reflect.Float32:
-		n, err := VarIntOut[uint32](decoder.reader)
-		if err != nil {
-			return err
-		}
-
-		value.SetFloat(floatFromBits(n))
-		return nil
-	case reflect.Float64:
-		n, err := VarIntOut[uint64](decoder.reader)
-		if err != nil {
-			return err
-		}
-
-		value.SetFloat(floatFromBits(n))
-		return nil
-	case reflect.Complex64:
-		r, err := VarIntOut[uint32](decoder.reader)
-		if err != nil {
-			return err
-		}
+		return decoder.defined(value)
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return decoder.float(value)
+	case reflect.Array:
+		if found, err := decoder.bulk(value); found {
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return err
+			}
 
-		i, err := VarIntOut[uint32](decoder.reader)
-		if err != nil {
-			return err
+			return decoder.defined(value)
 		}
 
-		value.SetComplex(complex(floatFromBits(r), floatFromBits(i)))
-		return nil
-	case reflect.Complex128:
-		r, err := VarIntOut[uint64](decoder.reader)
-		if err != nil {
-			return err
-		}
+		for i := 0; i < value.Len(); i++ {
+			if err := decoder.done(); err != nil {
+				return err
+			}
 
-		i, err := VarIntOut[uint64](decoder.reader)
-		if err != nil {
-			return err
+			if err := decoder.decode(value.Index(i)); err != nil && err != io.EOF {
+				return err
+			}
 		}
 
-		value.SetComplex(complex(floatFromBits(r), floatFromBits(i)))
 		return nil
-	case reflect.Array:
-		for i := 0; i < value.Len(); i++ {
-			if err := decoder.Decode(value.Index(i)); err != nil && err != io.EOF {
-				return err
-			}
+	case reflect.Chan:
+		if decoder.options.stream {
+			return decoder.stream(value)
 		}
 
 		return nil
-	case reflect.Chan, reflect.Func:
+	case reflect.Func:
 		return nil
 	case reflect.Interface:
 		if value.Kind() == reflect.Invalid {
 			return nil
 		}
 
+		options := decoder.options
+		defer func() {
+			decoder.options = options
+		}()
+
 		found, t, err := decoder.getType()
 		if err != nil {
 			return err
@@ -197,9 +164,13 @@ func (decoder *Decoder) Decode(v interface{}) error {
 			return nil
 		}
 
+		if !t.AssignableTo(value.Type()) {
+			return fmt.Errorf("%w: %v is not %v", NotImplemented, t, value.Type())
+		}
+
 		if found {
 			ptr := reflect.New(t)
-			if _, err = mkind.Run(t, decoder, ptr.Elem()); err != nil {
+			if _, err = decoder.codec.kinds.Run(t, decoder, ptr.Elem()); err != nil {
 				return err
 			}
 
@@ -207,13 +178,13 @@ func (decoder *Decoder) Decode(v interface{}) error {
 			return nil
 		}
 
-		if t.Kind() == reflect.Struct {
+		if t == reflect.TypeFor[*Struct]() {
 			return decoder.structs(value)
 		}
 
 		ptr := reflect.New(t).Elem()
 
-		if err = decoder.Decode(ptr); err != nil {
+		if err = decoder.decode(ptr); err != nil {
 			return err
 		}
 
@@ -231,13 +202,17 @@ func (decoder *Decoder) Decode(v interface{}) error {
 		valueType := value.Type().Elem()
 
 		for i := 0; i < size; i++ {
+			if err = decoder.done(); err != nil {
+				return err
+			}
+
 			mk := reflect.New(keyType).Elem()
-			if err = decoder.Decode(mk); err != nil {
+			if err = decoder.decode(mk); err != nil {
 				return err
 			}
 
 			mv := reflect.New(valueType).Elem()
-			if err = decoder.Decode(mv); err != nil {
+			if err = decoder.decode(mv); err != nil {
 				return err
 			}
 
@@ -246,12 +221,30 @@ func (decoder *Decoder) Decode(v interface{}) error {
 
 		return nil
 	case reflect.Pointer:
+		// A nil pointer to a struct is written as a zero struct, so it stays nil.
+		if elem := value.Type().Elem(); fielded(decoder.codec.kinds, elem) {
+			ptr := reflect.New(elem)
+
+			written, err := decoder.typed(ptr.Elem())
+			if err != nil {
+				return err
+			}
+
+			if written {
+				value.Set(ptr)
+			} else {
+				value.SetZero()
+			}
+
+			return nil
+		}
+
 		Zero(value)
 		for value.Kind() == reflect.Pointer {
 			value = value.Elem()
 		}
 
-		return decoder.Decode(value)
+		return decoder.decode(value)
 	case reflect.Slice:
 		size, err := VarIntOut[int](decoder.reader)
 		if err != nil {
@@ -260,8 +253,20 @@ func (decoder *Decoder) Decode(v interface{}) error {
 
 		value.Set(reflect.MakeSlice(value.Type(), size, size))
 
+		if found, err := decoder.bulk(value); found {
+			if err != nil {
+				return err
+			}
+
+			return decoder.defined(value)
+		}
+
 		for i := 0; i < size; i++ {
-			if err = decoder.Decode(value.Index(i)); err != nil {
+			if err = decoder.done(); err != nil {
+				return err
+			}
+
+			if err = decoder.decode(value.Index(i)); err != nil {
 				return err
 			}
 		}
@@ -275,36 +280,80 @@ func (decoder *Decoder) Decode(v interface{}) error {
 
 		data := make([]byte, size)
 
-		if _, err = decoder.reader.Read(data); err != nil {
+		if _, err = io.ReadFull(decoder.reader, data); err != nil {
 			return err
 		}
 
 		value.SetString(string(data))
 		return nil
 	case reflect.Struct:
-		fields := (&Struct{}).fields(value)
+		_, err := decoder.typed(value)
+		return err
+	}
 
-		for i := 0; i < len(fields); i++ {
-			tag, err := VarIntOut[int](decoder.reader)
-			if err != nil {
-				return err
-			}
+	return Invalid
+}
 
-			field, ok := fields[tag]
-			if !ok {
-				continue
-			}
+// typed decodes a struct by its fields, reporting false if it was written as a zero struct.
+func (decoder *Decoder) typed(value reflect.Value) (bool, error) {
+	fields := typeFields(value.Type())
 
-			Zero(field)
-			if err = decoder.Decode(field); err != nil {
-				return err
-			}
+	if fields.unknown >= 0 {
+		return decoder.framed(value, fields)
+	}
+
+	for i := 0; i < len(fields.list); i++ {
+		if err := decoder.done(); err != nil {
+			return true, err
 		}
 
-		return nil
+		tag, err := VarIntOut[int](decoder.reader)
+		if err != nil {
+			return true, err
+		}
+
+		// A zero struct is written as a single zero instead of its fields.
+		if _, ok := fields.tags[0]; i == 0 && tag == 0 && !ok {
+			return false, nil
+		}
+
+		f, ok := fields.tags[tag]
+		if !ok {
+			continue
+		}
+
+		if err = decoder.field(value, fields.list[f]); err != nil {
+			return true, err
+		}
 	}
 
-	return Invalid
+	return true, fields.oneof(value)
+}
+
+func (decoder *Decoder) field(value reflect.Value, f field) error {
+	field := value.Field(f.index)
+
+	options := decoder.options
+	defer func() {
+		decoder.options = options
+	}()
+
+	decoder.options = options.with(f)
+
+	mask, masked := options.mask.field(f.tag)
+	decoder.options.mask = mask
+
+	// Fields out of the mask are still read, but into a value thrown away.
+	if !masked {
+		field = reflect.New(field.Type()).Elem()
+	}
+
+	if f.oneof != "" && field.Kind() == reflect.Pointer {
+		return decoder.member(field)
+	}
+
+	Zero(field)
+	return decoder.decode(field)
 }
 
 func (decoder *Decoder) structs(value reflect.Value) error {
@@ -314,17 +363,36 @@ func (decoder *Decoder) structs(value reflect.Value) error {
 	}
 
 	s := &Struct{
-		m: make(map[int]reflect.Value),
+		m:     make(map[int]reflect.Value),
+		codec: decoder.codec,
+	}
+
+	if value.Type() == reflect.TypeFor[Struct]() {
+		value.Set(reflect.ValueOf(s).Elem())
+	} else {
+		value.Set(reflect.ValueOf(s))
 	}
 
-	value.Set(reflect.ValueOf(s))
+	options := decoder.options
+	defer func() {
+		decoder.options = options
+	}()
 
 	for i := 0; i < size; i++ {
+		if err = decoder.done(); err != nil {
+			return err
+		}
+
+		decoder.options = options
+
 		tag, err := VarIntOut[int](decoder.reader)
 		if err != nil {
 			return err
 		}
 
+		mask, ok := options.mask.field(tag)
+		decoder.options.mask = mask
+
 		found, t, err := decoder.getType()
 		if err != nil {
 			return err
@@ -335,15 +403,18 @@ func (decoder *Decoder) structs(value reflect.Value) error {
 		if found {
 			ptr = reflect.New(t).Elem()
 
-			if _, err = mkind.Run(t, decoder, ptr); err != nil {
+			if _, err = decoder.codec.kinds.Run(t, decoder, ptr); err != nil {
 				return err
 			}
 
-			s.m[tag] = ptr
+			if ok {
+				s.m[tag] = ptr
+			}
+
 			continue
 		}
 
-		if t.Kind() == reflect.Struct {
+		if t == reflect.TypeFor[*Struct]() {
 			ptr = reflect.New(reflect.TypeFor[interface{}]()).Elem()
 			if err = decoder.structs(ptr); err != nil {
 				return err
@@ -351,21 +422,48 @@ func (decoder *Decoder) structs(value reflect.Value) error {
 		} else {
 			ptr = reflect.New(t).Elem()
 
-			if err = decoder.Decode(ptr); err != nil {
+			if err = decoder.decode(ptr); err != nil {
 				return err
 			}
 		}
 
-		s.m[tag] = ptr
+		if ok {
+			s.m[tag] = ptr
+		}
 	}
 
 	return nil
 }
 
-func NewDecoder(reader io.Reader) *Decoder {
-	return &Decoder{
-		reader: reader,
+// Reset makes the decoder read from reader, allowing it to be reused.
+func (decoder *Decoder) Reset(reader io.Reader) {
+	decoder.reader = reader
+}
+
+func NewDecoder(reader io.Reader, opts ...Option) *Decoder {
+	return std.NewDecoder(reader, opts...)
+}
+
+// elem reads the kind of array and slice elements, packed and delta are only changed for elements
+// they apply to, as both only prefix those, so a key type keeps them while its map value type is read.
+func (decoder *Decoder) elem() (bool, reflect.Type, error) {
+	packed, delta := decoder.options.packed, decoder.options.delta
+	decoder.options.packed, decoder.options.delta = false, false
+
+	found, t, err := decoder.getType()
+	if err != nil {
+		return found, t, err
+	}
+
+	if t == nil || !packable(decoder.codec.kinds, t) {
+		decoder.options.packed = packed
 	}
+
+	if t == nil || !deltable(decoder.codec.kinds, t) {
+		decoder.options.delta = delta
+	}
+
+	return found, t, nil
 }
 
 func (decoder *Decoder) getType() (bool, reflect.Type, error) {
@@ -379,6 +477,22 @@ func (decoder *Decoder) getType() (bool, reflect.Type, error) {
 		return false, nil, nil
 	case reflect.Bool:
 		return false, reflect.TypeFor[bool](), nil
+	case kindPackedBool:
+		decoder.options.packed = true
+		return false, reflect.TypeFor[bool](), nil
+	case kindDelta:
+		found, t, err := decoder.getType()
+		decoder.options.delta = true
+
+		return found, t, err
+	case kindStream:
+		_, t, err := decoder.getType()
+		if err != nil {
+			return false, nil, err
+		}
+
+		decoder.options.stream = true
+		return false, reflect.ChanOf(reflect.BothDir, t), nil
 	case reflect.Int:
 		return false, reflect.TypeFor[int](), nil
 	case reflect.Int8:
@@ -402,12 +516,28 @@ func (decoder *Decoder) getType() (bool, reflect.Type, error) {
 	case reflect.Uintptr:
 		return false, reflect.TypeFor[uintptr](), nil
 	case reflect.Float32:
+		decoder.options.fixed = false
 		return false, reflect.TypeFor[float32](), nil
 	case reflect.Float64:
+		decoder.options.fixed = false
 		return false, reflect.TypeFor[float64](), nil
 	case reflect.Complex64:
+		decoder.options.fixed = false
 		return false, reflect.TypeFor[complex64](), nil
 	case reflect.Complex128:
+		decoder.options.fixed = false
+		return false, reflect.TypeFor[complex128](), nil
+	case kindFixedFloat32:
+		decoder.options.fixed = true
+		return false, reflect.TypeFor[float32](), nil
+	case kindFixedFloat64:
+		decoder.options.fixed = true
+		return false, reflect.TypeFor[float64](), nil
+	case kindFixedComplex64:
+		decoder.options.fixed = true
+		return false, reflect.TypeFor[complex64](), nil
+	case kindFixedComplex128:
+		decoder.options.fixed = true
 		return false, reflect.TypeFor[complex128](), nil
 	case reflect.Interface:
 		return false, reflect.TypeFor[interface{}](), nil
@@ -434,12 +564,12 @@ func (decoder *Decoder) getType() (bool, reflect.Type, error) {
 			di = append(di, n)
 		}
 
-		found, t, err := decoder.getType()
+		found, t, err := decoder.elem()
 		if err != nil {
 			return found, nil, err
 		}
 
-		return found, fromDepth(t, d, di), nil
+		return false, fromDepth(t, d, di), nil
 	case reflect.Slice:
 		d, err := VarIntOut[int](decoder.reader)
 		if err != nil {
@@ -464,34 +594,66 @@ func (decoder *Decoder) getType() (bool, reflect.Type, error) {
 			}
 		}
 
-		found, t, err := decoder.getType()
+		found, t, err := decoder.elem()
 		if err != nil {
 			return found, nil, err
 		}
 
-		return found, fromDepth(t, d, di), nil
+		return false, fromDepth(t, d, di), nil
 	case reflect.Map:
 		found, key, err := decoder.getType()
 		if err != nil {
 			return found, nil, err
 		}
 
-		found2, value, err := decoder.getType()
+		found, value, err := decoder.getType()
 		if err != nil {
-			return found2, nil, err
-		}
-
-		if !found && found2 {
-			found = true
+			return found, nil, err
 		}
 
-		return found, reflect.MapOf(key, value), nil
+		return false, reflect.MapOf(key, value), nil
 	case reflect.Struct:
 		return false, reflect.TypeFor[*Struct](), nil
+	case kindNamed:
+		var name string
+		if err = decoder.Decode(&name); err != nil {
+			return false, nil, err
+		}
+
+		t, ok := decoder.codec.names.load(name)
+		if !ok {
+			return false, nil, fmt.Errorf("%w: %s", UnknownName, name)
+		}
+
+		return false, t, nil
+	case kindNamedID:
+		id, err := VarIntOut[int](decoder.reader)
+		if err != nil {
+			return false, nil, err
+		}
+
+		t, ok := decoder.codec.names.load(id)
+		if !ok {
+			return false, nil, fmt.Errorf("%w: %d", UnknownName, id)
+		}
+
+		return false, t, nil
+	case kindImpl:
+		id, err := VarIntOut[int](decoder.reader)
+		if err != nil {
+			return false, nil, err
+		}
+
+		t, ok := decoder.codec.impls.load(id)
+		if !ok {
+			return false, nil, fmt.Errorf("%w: %d", UnknownImpl, id)
+		}
+
+		return false, t, nil
 	case reflect.Chan, reflect.Func, reflect.Pointer, reflect.UnsafePointer:
 		return false, nil, nil
 	default:
-		_, lt := mkind.Load(kind)
+		_, lt := decoder.codec.kinds.Load(kind)
 		if lt != nil {
 			return true, lt, nil
 		}
@@ -20,9 +20,15 @@
 package bin
 
 import (
+	"context"
+	"errors"
 	"github.com/Dviih/bin/buffer"
+	"io"
+	"net"
+	"os"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestDecoderNil(t *testing.T) {
@@ -100,6 +106,21 @@ func TestDecoderFloat(t *testing.T) {
 	}
 }
 
+func TestDecoderFixedFloat(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder(buffer.From(expectedFixedFloat), Fixed())
+
+	var f float64
+	if err := decoder.Decode(&f); err != nil {
+		t.Error("failed to decode fixed float")
+	}
+
+	if f != Float {
+		t.Errorf("expected %v, received: %v", Float, f)
+	}
+}
+
 func TestDecoderComplex(t *testing.T) {
 	t.Parallel()
 
@@ -175,6 +196,51 @@ func TestDecoderString(t *testing.T) {
 	}
 }
 
+func TestDecoderBytes(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder(buffer.From(expectedBytes))
+
+	var v []byte
+	if err := decoder.Decode(&v); err != nil {
+		t.Error("failed to decode bytes")
+	}
+
+	if !reflect.DeepEqual(v, Bytes) {
+		t.Errorf("expected %v, received: %v", Bytes, v)
+	}
+}
+
+func TestDecoderBytes2(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder(buffer.From(expectedBytes2))
+
+	var v [4]byte
+	if err := decoder.Decode(&v); err != nil {
+		t.Error("failed to decode byte array")
+	}
+
+	if !reflect.DeepEqual(v, Bytes2) {
+		t.Errorf("expected %v, received: %v", Bytes2, v)
+	}
+}
+
+func TestDecoderFloats(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder(buffer.From(expectedFloats))
+
+	var v []float64
+	if err := decoder.Decode(&v); err != nil {
+		t.Error("failed to decode floats")
+	}
+
+	if !reflect.DeepEqual(v, Floats) {
+		t.Errorf("expected %v, received: %v", Floats, v)
+	}
+}
+
 func TestDecoderStruct(t *testing.T) {
 	t.Parallel()
 
@@ -487,3 +553,108 @@ func TestDecoderInterfaceStructAll(t *testing.T) {
 		t.Errorf("expected %v, received: %v", StructAllValue, st)
 	}
 }
+
+func TestDecoderReset(t *testing.T) {
+	t.Parallel()
+
+	decoder := NewDecoder(buffer.New())
+
+	for i := 0; i < 2; i++ {
+		decoder.Reset(buffer.From(expectedStruct))
+
+		var st *Struct1
+		if err := decoder.Decode(&st); err != nil {
+			t.Error("failed to decode after reset")
+		}
+
+		if !reflect.DeepEqual(st, Struct2) {
+			t.Errorf("expected %v, received: %v", Struct2, st)
+		}
+	}
+}
+
+func TestDecoderContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var st *StructAll
+	if err := NewDecoder(buffer.From(expectedStructAll)).DecodeContext(ctx, &st); err != context.Canceled {
+		t.Errorf("expected %v, received: %v", context.Canceled, err)
+	}
+}
+
+func TestDecoderContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	r, w := net.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Nothing is ever written, only the deadline unblocks the read.
+	var st *Struct1
+	if err := NewDecoder(r).DecodeContext(ctx, &st); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, received: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestDecoderContextConnDeadline(t *testing.T) {
+	t.Parallel()
+
+	r, w := net.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	_ = r.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// The wrapper hides the conn, so its own deadline is the one expiring.
+	reader := struct{ io.Reader }{r}
+	start := time.Now()
+
+	var st *Struct1
+	if err := NewDecoder(reader).DecodeContext(ctx, &st); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("expected %v, received: %v", os.ErrDeadlineExceeded, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to return at the conn deadline, received: %v", elapsed)
+	}
+}
+
+func TestDecoderContextWrite(t *testing.T) {
+	t.Parallel()
+
+	r, w := net.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Read(make([]byte, 1))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		var st *Struct1
+		done <- NewDecoder(r).DecodeContext(ctx, &st)
+	}()
+
+	// Writing on the same conn outlives the context, only reading must expire.
+	if _, err := r.Write([]byte{1}); err != nil {
+		t.Errorf("expected to write, received: %v", err)
+	}
+
+	if err := <-done; err != context.DeadlineExceeded {
+		t.Errorf("expected %v, received: %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"github.com/Dviih/bin/kind"
+	"reflect"
+)
+
+// Kind placed before the element kind of interfaced arrays and slices of delta integers.
+const kindDelta = 75
+
+func deltable(kinds *kind.Map, t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return false
+	}
+
+	return !registered(kinds, t)
+}
+
+func zigzag(n int64) uint64 {
+	return uint64(n<<1) ^ uint64(n>>63)
+}
+
+func unzigzag(n uint64) int64 {
+	return int64(n>>1) ^ -int64(n&1)
+}
+
+func integer(value reflect.Value) uint64 {
+	if value.CanInt() {
+		return uint64(value.Int())
+	}
+
+	return value.Uint()
+}
+
+func setInteger(value reflect.Value, n uint64) {
+	if value.CanInt() {
+		value.SetInt(int64(n))
+		return
+	}
+
+	value.SetUint(n)
+}
+
+// delta writes the first integer and then the difference from the previous one, all zigzagged.
+func (encoder *Encoder) delta(value reflect.Value) (bool, error) {
+	if !encoder.options.delta || !deltable(encoder.codec.kinds, value.Type().Elem()) {
+		return false, nil
+	}
+
+	b := encoder.scratch[:0]
+
+	var previous uint64
+
+	for i := 0; i < value.Len(); i++ {
+		if len(b) >= bulkSize {
+			if _, err := encoder.writer.Write(b); err != nil {
+				return true, err
+			}
+
+			b = b[:0]
+		}
+
+		n := integer(value.Index(i))
+
+		b = appendVarInt(b, zigzag(int64(n-previous)))
+		previous = n
+	}
+
+	encoder.scratch = b[:0]
+
+	_, err := encoder.writer.Write(b)
+	return true, err
+}
+
+func (decoder *Decoder) delta(value reflect.Value) (bool, error) {
+	if !decoder.options.delta || !deltable(decoder.codec.kinds, value.Type().Elem()) {
+		return false, nil
+	}
+
+	var previous uint64
+
+	for i := 0; i < value.Len(); i++ {
+		n, err := VarIntOut[uint64](decoder.reader)
+		if err != nil {
+			return true, err
+		}
+
+		previous += uint64(unzigzag(n))
+		setInteger(value.Index(i), previous)
+	}
+
+	return true, nil
+}
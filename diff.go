@@ -0,0 +1,381 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Operations of a change.
+const (
+	ChangeSet = iota + 1
+	ChangeDelete
+	ChangeResize
+)
+
+// Change is a single operation of a patch, Path is the same as taken by Struct.Lookup.
+// ChangeSet sets Value, ChangeDelete clears a field or removes a tag or map key
+// and ChangeResize sets the length of a slice to Value.
+type Change struct {
+	Op    int         `bin:"1"`
+	Path  string      `bin:"2"`
+	Value interface{} `bin:"3"`
+}
+
+// Diff returns the changes that make a into b, both must have the same type.
+func Diff(a, b interface{}) ([]Change, error) {
+	return std.Diff(a, b)
+}
+
+// Diff is the same as the package function using the kinds of the codec.
+func (codec *Codec) Diff(a, b interface{}) ([]Change, error) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() {
+		return nil, Mismatch
+	}
+
+	var changes []Change
+	codec.diff(va, vb, "", &changes)
+
+	return changes, nil
+}
+
+func (codec *Codec) diff(a, b reflect.Value, path string, changes *[]Change) {
+	join := func(segment string) string {
+		if path == "" {
+			return segment
+		}
+
+		return path + "." + segment
+	}
+
+	set := func() {
+		*changes = append(*changes, Change{Op: ChangeSet, Path: path, Value: b.Interface()})
+	}
+
+	clear := func() {
+		*changes = append(*changes, Change{Op: ChangeDelete, Path: path})
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		switch {
+		case a.IsNil() && b.IsNil():
+		case b.IsNil():
+			clear()
+		case a.IsNil() || a.Elem().Type() != b.Elem().Type():
+			set()
+		default:
+			codec.diff(a.Elem(), b.Elem(), path, changes)
+		}
+	case reflect.Struct:
+		if a.Type() == reflect.TypeFor[Struct]() {
+			sa, sb := a.Interface().(Struct), b.Interface().(Struct)
+
+			for _, tag := range sa.Tags() {
+				if _, ok := sb.m[tag]; !ok {
+					*changes = append(*changes, Change{Op: ChangeDelete, Path: join(strconv.Itoa(tag))})
+				}
+			}
+
+			for _, tag := range sb.Tags() {
+				va, ok := sa.m[tag]
+				vb := sb.m[tag]
+
+				if !ok {
+					*changes = append(*changes, Change{Op: ChangeSet, Path: join(strconv.Itoa(tag)), Value: vb.Interface()})
+					continue
+				}
+
+				codec.diff(reflect.ValueOf(va.Interface()), reflect.ValueOf(vb.Interface()), join(strconv.Itoa(tag)), changes)
+			}
+
+			return
+		}
+
+		// Structs of kinds and ones without fields, such as time.Time without its kind, are compared as a whole.
+		if registered(codec.kinds, a.Type()) || len(typeFields(a.Type()).list) == 0 {
+			if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+				set()
+			}
+
+			return
+		}
+
+		for _, f := range typeFields(a.Type()).list {
+			fa, fb := a.Field(f.index), b.Field(f.index)
+			p := join(strconv.Itoa(f.tag))
+
+			if fb.IsZero() && !fa.IsZero() {
+				*changes = append(*changes, Change{Op: ChangeDelete, Path: p})
+				continue
+			}
+
+			codec.diff(fa, fb, p, changes)
+		}
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			*changes = append(*changes, Change{Op: ChangeResize, Path: path, Value: b.Len()})
+		}
+
+		for i := 0; i < b.Len(); i++ {
+			p := join("[" + strconv.Itoa(i) + "]")
+
+			if i >= a.Len() {
+				*changes = append(*changes, Change{Op: ChangeSet, Path: p, Value: b.Index(i).Interface()})
+				continue
+			}
+
+			codec.diff(a.Index(i), b.Index(i), p, changes)
+		}
+	case reflect.Array:
+		for i := 0; i < b.Len(); i++ {
+			codec.diff(a.Index(i), b.Index(i), join("["+strconv.Itoa(i)+"]"), changes)
+		}
+	case reflect.Map:
+		for _, key := range a.MapKeys() {
+			if !b.MapIndex(key).IsValid() {
+				*changes = append(*changes, Change{Op: ChangeDelete, Path: join("[" + fmt.Sprint(key.Interface()) + "]")})
+			}
+		}
+
+		m := b.MapRange()
+
+		for m.Next() {
+			p := join("[" + fmt.Sprint(m.Key().Interface()) + "]")
+
+			v := a.MapIndex(m.Key())
+			if !v.IsValid() {
+				*changes = append(*changes, Change{Op: ChangeSet, Path: p, Value: m.Value().Interface()})
+				continue
+			}
+
+			codec.diff(v, m.Value(), p, changes)
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			set()
+		}
+	}
+}
+
+// Patch applies changes to the value v points to, values are converted as TryAs does.
+func Patch(v interface{}, changes []Change) error {
+	value := reflect.ValueOf(v)
+
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return CantSet
+	}
+
+	for _, change := range changes {
+		if err := patch(value.Elem(), segments(change.Path), change); err != nil {
+			return &FieldError{Path: change.Path, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func patch(value reflect.Value, path []string, change Change) error {
+	if len(path) == 0 {
+		switch change.Op {
+		case ChangeSet:
+			var errs []error
+			strict(reflect.ValueOf(change.Value), value, "", &errs)
+
+			return errors.Join(errs...)
+		case ChangeDelete:
+			value.SetZero()
+			return nil
+		case ChangeResize:
+			n, err := TryAs[int](change.Value)
+			if err != nil {
+				return err
+			}
+
+			if value.Kind() != reflect.Slice || n < 0 {
+				return Invalid
+			}
+
+			if n > value.Cap() {
+				s := reflect.MakeSlice(value.Type(), n, n)
+				reflect.Copy(s, value)
+
+				value.Set(s)
+				return nil
+			}
+
+			value.SetLen(n)
+			return nil
+		default:
+			return Invalid
+		}
+	}
+
+	segment, rest := path[0], path[1:]
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+
+		return patch(value.Elem(), path, change)
+	case reflect.Interface:
+		if value.IsNil() {
+			return fmt.Errorf("%w: %q on nil", InvalidPath, segment)
+		}
+
+		tmp := reflect.New(value.Elem().Type()).Elem()
+		tmp.Set(value.Elem())
+
+		if err := patch(tmp, path, change); err != nil {
+			return err
+		}
+
+		value.Set(tmp)
+		return nil
+	case reflect.Struct:
+		tag, err := strconv.Atoi(segment)
+		if err != nil {
+			return fmt.Errorf("%w: %q is not a tag", InvalidPath, segment)
+		}
+
+		if value.Type() == reflect.TypeFor[Struct]() {
+			s := value.Addr().Interface().(*Struct)
+
+			if len(rest) == 0 {
+				switch change.Op {
+				case ChangeSet:
+					s.Set(tag, change.Value)
+					return nil
+				case ChangeDelete:
+					s.Delete(tag)
+					return nil
+				}
+			}
+
+			v, ok := s.m[tag]
+			if !ok {
+				return fmt.Errorf("%w: %q not found", InvalidPath, segment)
+			}
+
+			tmp := reflect.New(v.Type()).Elem()
+			tmp.Set(v)
+
+			if err = patch(tmp, rest, change); err != nil {
+				return err
+			}
+
+			s.m[tag] = tmp
+			return nil
+		}
+
+		f, ok := typeFields(value.Type()).tags[tag]
+		if !ok {
+			return fmt.Errorf("%w: %q not found", InvalidPath, segment)
+		}
+
+		return patch(value.Field(typeFields(value.Type()).list[f].index), rest, change)
+	case reflect.Array, reflect.Slice:
+		i, err := strconv.Atoi(index(segment))
+		if err != nil || i < 0 || i >= value.Len() {
+			return fmt.Errorf("%w: %q out of range", InvalidPath, segment)
+		}
+
+		return patch(value.Index(i), rest, change)
+	case reflect.Map:
+		key, err := mapKey(value, index(segment))
+		if err != nil {
+			return err
+		}
+
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
+		}
+
+		if len(rest) == 0 && change.Op == ChangeDelete {
+			value.SetMapIndex(key, reflect.Value{})
+			return nil
+		}
+
+		tmp := reflect.New(value.Type().Elem()).Elem()
+		if v := value.MapIndex(key); v.IsValid() {
+			tmp.Set(v)
+		}
+
+		if err = patch(tmp, rest, change); err != nil {
+			return err
+		}
+
+		value.SetMapIndex(key, tmp)
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q on %v", InvalidPath, segment, value.Type())
+}
+
+// mapKey finds a key printed as key, or parses it as the key type of the map.
+func mapKey(value reflect.Value, key string) (reflect.Value, error) {
+	m := value.MapRange()
+
+	for m.Next() {
+		if fmt.Sprint(m.Key().Interface()) == key {
+			return m.Key(), nil
+		}
+	}
+
+	k := reflect.New(value.Type().Key()).Elem()
+
+	var err error
+
+	switch k.Kind() {
+	case reflect.String:
+		k.SetString(key)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(key)
+		k.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(key, 10, k.Type().Bits())
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(key, 10, k.Type().Bits())
+		k.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(key, k.Type().Bits())
+		k.SetFloat(f)
+	default:
+		err = Mismatch
+	}
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: [%s] is not %v", InvalidPath, key, k.Type())
+	}
+
+	return k, nil
+}
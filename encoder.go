@@ -20,92 +20,84 @@
 package bin
 
 import (
+	"context"
 	"io"
 	"reflect"
-	"strconv"
+	"slices"
 )
 
 type Encoder struct {
-	writer io.Writer
+	writer  io.Writer
+	scratch []byte
+	options options
+	codec   *Codec
+	ctx     context.Context
 }
 
 func (encoder *Encoder) Encode(v interface{}) error {
-	value := Value(v)
+	if v == nil {
+		return encoder.writeByte(0)
+	}
 
-	if v != nil {
-		found, err := mkind.Run(value.Type(), encoder, value)
-		if err != nil {
-			return err
-		}
+	return encoder.encode(Value(v))
+}
 
-		if found {
-			return nil
-		}
+func (encoder *Encoder) encode(value reflect.Value) error {
+	if !value.IsValid() {
+		return Invalid
+	}
+
+	found, err := encoder.codec.kinds.Run(value.Type(), encoder, value)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		return nil
 	}
 
 	switch value.Kind() {
 	case reflect.Invalid, reflect.Uintptr, reflect.UnsafePointer:
-		if v == nil {
-			return encoder.Encode(0)
-		}
-
 		return Invalid
 	case reflect.Bool:
 		if value.Bool() {
-			_, err := encoder.writer.Write([]byte{255})
-			return err
+			return encoder.writeByte(255)
 		}
 
-		return encoder.Encode(0)
+		return encoder.writeByte(0)
 	case reflect.Int8:
-		if _, err := encoder.writer.Write([]byte{byte(value.Int())}); err != nil {
-			return err
-		}
-		return nil
+		return encoder.writeByte(byte(value.Int()))
 	case reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64:
-		if err := VarIntIn(encoder.writer, value.Int()); err != nil {
-			return err
-		}
-		return nil
+		return VarIntIn(encoder.writer, value.Int())
 	case reflect.Uint8:
-		if _, err := encoder.writer.Write([]byte{byte(value.Uint())}); err != nil {
-			return err
-		}
-		return nil
+		return encoder.writeByte(byte(value.Uint()))
 	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if err := VarIntIn(encoder.writer, value.Uint()); err != nil {
-			return err
-		}
-
-		return nil
-	case reflect.Float32:
-		return encoder.Encode(floatToBits(float32(value.Float())))
-	case reflect.Float64:
-		return encoder.Encode(floatToBits(value.Float()))
-	case reflect.Complex64:
-		c := complex64(value.Complex())
-
-		if err := encoder.Encode(floatToBits(real(c))); err != nil {
-			return err
-		}
-
-		return encoder.Encode(floatToBits(imag(c)))
-	case reflect.Complex128:
-		c := value.Complex()
-
-		if err := encoder.Encode(floatToBits(real(c))); err != nil {
+		return VarIntIn(encoder.writer, value.Uint())
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return encoder.float(value)
+	case reflect.Array:
+		if found, err := encoder.bulk(value); found {
 			return err
 		}
 
-		return encoder.Encode(floatToBits(imag(c)))
-	case reflect.Array:
 		for i := 0; i < value.Len(); i++ {
-			if err := encoder.Encode(value.Index(i)); err != nil {
+			if err := encoder.done(); err != nil {
+				return err
+			}
+
+			if err := encoder.encode(value.Index(i)); err != nil {
 				return err
 			}
 		}
-	case reflect.Chan, reflect.Func:
-		// Channels and Functions aren't supported.
+	case reflect.Chan:
+		// Channels are only supported as streams.
+		if encoder.options.stream {
+			return encoder.stream(value)
+		}
+
+		return nil
+	case reflect.Func:
+		// Functions aren't supported.
 		return nil
 	case reflect.Interface:
 		if value.IsNil() {
@@ -122,12 +114,28 @@ func (encoder *Encoder) Encode(v interface{}) error {
 
 		value = Abs[reflect.Value](value)
 
-		if n, _ := mkind.Load(value.Type()); n != 0 {
-			if err := encoder.Encode(n); err != nil {
+		if value.Type() == reflect.TypeFor[Struct]() {
+			if err := VarIntIn(encoder.writer, reflect.Struct); err != nil {
+				return err
+			}
+
+			return encoder.encode(value)
+		}
+
+		if n, _ := encoder.codec.kinds.Load(value.Type()); n != 0 {
+			if err := VarIntIn(encoder.writer, n); err != nil {
 				return err
 			}
 
-			_, err := mkind.Run(n, encoder, value)
+			_, err := encoder.codec.kinds.Run(n, encoder, value)
+			return err
+		}
+
+		if found, err := encoder.named(value); found {
+			return err
+		}
+
+		if found, err := encoder.impl(value); found {
 			return err
 		}
 
@@ -135,18 +143,22 @@ func (encoder *Encoder) Encode(v interface{}) error {
 		case reflect.Array, reflect.Slice:
 			_, elem := KeyElem(value)
 
-			switch Abs[reflect.Type](elem).Kind() {
-			case reflect.Struct:
+			switch {
+			case isStruct(encoder.codec.kinds, elem):
 				if err := encoder.getType(reflect.New(reflect.TypeFor[[]interface{}]()).Elem()); err != nil {
 					return err
 				}
 
-				if err := encoder.Encode(value.Len()); err != nil {
+				if err := VarIntIn(encoder.writer, value.Len()); err != nil {
 					return err
 				}
 
 				for i := 0; i < value.Len(); i++ {
-					if err := encoder.Encode(interfaces(value.Index(i))); err != nil {
+					if err := encoder.done(); err != nil {
+						return err
+					}
+
+					if err := encoder.encode(interfaces(encoder.codec, value.Index(i))); err != nil {
 						return err
 					}
 				}
@@ -164,24 +176,28 @@ func (encoder *Encoder) Encode(v interface{}) error {
 				return TypeMustBeComparable
 			}
 
-			switch Abs[reflect.Type](elem).Kind() {
-			case reflect.Struct:
+			switch {
+			case isStruct(encoder.codec.kinds, elem):
 				if err := encoder.getType(reflect.New(reflect.MapOf(key, reflect.TypeFor[interface{}]())).Elem()); err != nil {
 					return err
 				}
 
-				if err := encoder.Encode(value.Len()); err != nil {
+				if err := VarIntIn(encoder.writer, value.Len()); err != nil {
 					return err
 				}
 
 				m := value.MapRange()
 
 				for m.Next() {
-					if err := encoder.Encode(m.Key()); err != nil {
+					if err := encoder.done(); err != nil {
+						return err
+					}
+
+					if err := encoder.encode(m.Key()); err != nil {
 						return err
 					}
 
-					if err := encoder.Encode(interfaces(m.Value())); err != nil {
+					if err := encoder.encode(interfaces(encoder.codec, m.Value())); err != nil {
 						return err
 					}
 				}
@@ -205,24 +221,28 @@ func (encoder *Encoder) Encode(v interface{}) error {
 			}
 		}
 
-		return encoder.Encode(value)
+		return encoder.encode(value)
 	case reflect.Map:
 		if !value.Type().Key().Comparable() {
 			return TypeMustBeComparable
 		}
 
-		if err := encoder.Encode(value.Len()); err != nil {
+		if err := VarIntIn(encoder.writer, value.Len()); err != nil {
 			return err
 		}
 
 		m := value.MapRange()
 
 		for m.Next() {
-			if err := encoder.Encode(m.Key()); err != nil {
+			if err := encoder.done(); err != nil {
 				return err
 			}
 
-			if err := encoder.Encode(m.Value()); err != nil {
+			if err := encoder.encode(m.Key()); err != nil {
+				return err
+			}
+
+			if err := encoder.encode(m.Value()); err != nil {
 				return err
 			}
 		}
@@ -231,26 +251,38 @@ func (encoder *Encoder) Encode(v interface{}) error {
 			value = value.Elem()
 		}
 
-		return encoder.Encode(value)
+		return encoder.encode(value)
 	case reflect.Slice:
-		if err := encoder.Encode(value.Len()); err != nil {
+		if err := VarIntIn(encoder.writer, value.Len()); err != nil {
+			return err
+		}
+
+		if found, err := encoder.bulk(value); found {
 			return err
 		}
 
 		for i := 0; i < value.Len(); i++ {
-			if err := encoder.Encode(value.Index(i)); err != nil {
+			if err := encoder.done(); err != nil {
+				return err
+			}
+
+			if err := encoder.encode(value.Index(i)); err != nil {
 				return err
 			}
 		}
 	case reflect.String:
-		if err := encoder.Encode(value.Len()); err != nil {
+		if err := VarIntIn(encoder.writer, value.Len()); err != nil {
 			return err
 		}
 
-		if _, err := encoder.writer.Write([]byte(value.String())); err != nil {
+		if _, err := io.WriteString(encoder.writer, value.String()); err != nil {
 			return err
 		}
 	case reflect.Struct:
+		if value.Type() == reflect.TypeFor[Struct]() {
+			return encoder.dynamic(value.Interface().(Struct))
+		}
+
 		return encoder.structs(value, false)
 	}
 
@@ -258,74 +290,117 @@ func (encoder *Encoder) Encode(v interface{}) error {
 }
 
 func (encoder *Encoder) structs(value reflect.Value, kind bool) error {
-	t := value.Type()
+	fields := typeFields(value.Type())
+	if fields.err != nil {
+		return fields.err
+	}
 
-	for i := 0; i < value.NumField(); i++ {
-		field := value.Field(i)
-		if kind && field.IsZero() {
-			continue
+	if err := fields.oneof(value); err != nil {
+		return err
+	}
+
+	framed := !kind && fields.unknown >= 0
+
+	if framed {
+		if err := encoder.unknown(value, fields, true); err != nil {
+			return err
 		}
+	}
+
+	for _, f := range fields.list {
+		field := value.Field(f.index)
+
+		mask, ok := encoder.options.mask.field(f.tag)
+		if !ok {
+			if kind {
+				continue
+			}
 
-		ft := t.Field(i)
+			field = reflect.Zero(field.Type())
+		}
 
-		if !ft.IsExported() {
+		if kind && field.IsZero() {
 			continue
 		}
 
+		if err := encoder.done(); err != nil {
+			return err
+		}
+
 		kind := kind
-		if !kind && ft.Type.Kind() == reflect.Interface {
+		if !kind && field.Kind() == reflect.Interface {
 			kind = true
 		}
 
-		tag := i + 1
+		if err := VarIntIn(encoder.writer, f.tag); err != nil {
+			return err
+		}
 
-		if lookup, ok := ft.Tag.Lookup("bin"); ok {
-			if lookup == "-" {
-				continue
-			}
+		options := encoder.options
+		encoder.options = options.with(f)
+		encoder.options.mask = mask
 
-			n, err := strconv.Atoi(lookup)
-			if err != nil {
-				return err
-			}
+		write := encoder.field
+		if !kind && f.oneof != "" && field.Kind() == reflect.Pointer {
+			write = encoder.member
+		}
+
+		var err error
 
-			tag = n
+		if framed {
+			err = encoder.frame(func() error {
+				return write(field, kind)
+			})
+		} else {
+			err = write(field, kind)
 		}
 
-		if err := encoder.Encode(tag); err != nil {
+		encoder.options = options
+
+		if err != nil {
 			return err
 		}
+	}
 
-		if field.IsZero() {
-			if err := encoder.Encode(0); err != nil {
-				return err
-			}
+	if framed {
+		return encoder.unknown(value, fields, false)
+	}
 
-			continue
-		}
+	return nil
+}
 
-		lf, _ := mkind.Load(field.Type())
-		if lf != 0 {
-			if kind {
-				if err := encoder.Encode(lf); err != nil {
-					return err
-				}
-			}
+// dynamic writes a Struct as an interfaced struct without its kind, in ascending tag order.
+func (encoder *Encoder) dynamic(s Struct) error {
+	tags := s.Tags()
 
-			if _, err := mkind.Run(lf, encoder, field); err != nil {
-				return err
-			}
+	if encoder.options.mask != nil {
+		tags = slices.DeleteFunc(tags, func(tag int) bool {
+			_, ok := encoder.options.mask[tag]
+			return !ok
+		})
+	}
 
-			continue
-		} else if kind {
-			if err := encoder.Encode(Interface(field.Interface())); err != nil {
-				return err
-			}
+	if err := VarIntIn(encoder.writer, len(tags)); err != nil {
+		return err
+	}
 
-			continue
+	mask := encoder.options.mask
+	defer func() {
+		encoder.options.mask = mask
+	}()
+
+	for _, tag := range tags {
+		if err := encoder.done(); err != nil {
+			return err
 		}
 
-		if err := encoder.Encode(field); err != nil {
+		if err := VarIntIn(encoder.writer, tag); err != nil {
+			return err
+		}
+
+		encoder.options.mask = mask[tag]
+
+		if err := encoder.encode(encoder.codec.Interface(s.m[tag].Interface())); err != nil {
 			return err
 		}
 	}
@@ -333,11 +408,47 @@ func (encoder *Encoder) structs(value reflect.Value, kind bool) error {
 	return nil
 }
 
+func (encoder *Encoder) field(field reflect.Value, kind bool) error {
+	if field.IsZero() {
+		// Nil interfaces are written as their kind and value so the decoder reads both.
+		if field.Kind() == reflect.Interface {
+			return encoder.encode(field)
+		}
+
+		// Fixed words, bits and deltas can't be told from a zero byte, so the zero value is written as is.
+		if encoder.options.exact() && !kind {
+			return encoder.encode(reflect.Zero(Abs[reflect.Type](field.Type())))
+		}
+
+		return encoder.writeByte(0)
+	}
+
+	lf, _ := encoder.codec.kinds.Load(field.Type())
+	if lf != 0 {
+		if kind {
+			if err := VarIntIn(encoder.writer, lf); err != nil {
+				return err
+			}
+		}
+
+		_, err := encoder.codec.kinds.Run(lf, encoder, field)
+		return err
+	} else if kind {
+		return encoder.encode(encoder.codec.Interface(field.Interface()))
+	}
+
+	return encoder.encode(field)
+}
+
 func (encoder *Encoder) getType(value reflect.Value) error {
-	if err := encoder.Encode(value.Type().Kind()); err != nil {
+	if err := VarIntIn(encoder.writer, encoder.kind(value.Type())); err != nil {
 		return err
 	}
 
+	if registered(encoder.codec.kinds, value.Type()) {
+		return nil
+	}
+
 	switch value.Type().Kind() {
 	case reflect.Array:
 		dt, d, mixed, di := depth(value)
@@ -356,7 +467,7 @@ func (encoder *Encoder) getType(value reflect.Value) error {
 			}
 		}
 
-		if err := encoder.Encode(Abs[reflect.Type](dt).Kind()); err != nil {
+		if err := encoder.elem(Abs[reflect.Type](dt)); err != nil {
 			return err
 		}
 
@@ -380,9 +491,7 @@ func (encoder *Encoder) getType(value reflect.Value) error {
 			}
 		}
 
-		kind := Abs[reflect.Type](dt).Kind()
-
-		if err := encoder.Encode(kind); err != nil {
+		if err := encoder.elem(Abs[reflect.Type](dt)); err != nil {
 			return err
 		}
 
@@ -397,12 +506,19 @@ func (encoder *Encoder) getType(value reflect.Value) error {
 		}
 
 		return nil
+	case reflect.Chan:
+		if !encoder.options.stream {
+			return nil
+		}
+
+		return encoder.getType(reflect.New(value.Type().Elem()).Elem())
 	case reflect.Struct:
-		n := value.Type().NumField()
+		// Only fields are counted, an Unknown field isn't written by interfaced structs.
+		n := 0
 
-		for i := 0; i < value.Type().NumField(); i++ {
-			if value.Field(i).IsZero() {
-				n--
+		for _, f := range typeFields(value.Type()).list {
+			if _, ok := encoder.options.mask.field(f.tag); ok && !value.Field(f.index).IsZero() {
+				n++
 			}
 		}
 
@@ -416,6 +532,20 @@ func (encoder *Encoder) getType(value reflect.Value) error {
 	}
 }
 
-func NewEncoder(writer io.Writer) *Encoder {
-	return &Encoder{writer: writer}
+func (encoder *Encoder) writeByte(b byte) error {
+	if bw, ok := encoder.writer.(io.ByteWriter); ok {
+		return bw.WriteByte(b)
+	}
+
+	_, err := encoder.writer.Write([]byte{b})
+	return err
+}
+
+// Reset makes the encoder write to writer, allowing it to be reused.
+func (encoder *Encoder) Reset(writer io.Writer) {
+	encoder.writer = writer
+}
+
+func NewEncoder(writer io.Writer, opts ...Option) *Encoder {
+	return std.NewEncoder(writer, opts...)
 }
@@ -20,8 +20,10 @@
 package bin
 
 import (
+	"context"
 	"github.com/Dviih/bin/buffer"
 	"testing"
+	"time"
 )
 
 func TestEncoderNil(t *testing.T) {
@@ -99,6 +101,21 @@ func TestEncoderFloat(t *testing.T) {
 	}
 }
 
+func TestEncoderFixedFloat(t *testing.T) {
+	t.Parallel()
+
+	b := buffer.New()
+	encoder := NewEncoder(b, Fixed())
+
+	if err := encoder.Encode(Float); err != nil {
+		t.Error("failed to encode fixed float")
+	}
+
+	if string(b.Data()) != string(expectedFixedFloat) {
+		t.Errorf("expected %v, received: %v", expectedFixedFloat, b.Data())
+	}
+}
+
 func TestEncoderComplex(t *testing.T) {
 	t.Parallel()
 
@@ -174,6 +191,51 @@ func TestEncoderString(t *testing.T) {
 	}
 }
 
+func TestEncoderBytes(t *testing.T) {
+	t.Parallel()
+
+	b := buffer.New()
+	encoder := NewEncoder(b)
+
+	if err := encoder.Encode(Bytes); err != nil {
+		t.Error("failed to encode bytes")
+	}
+
+	if string(b.Data()) != string(expectedBytes) {
+		t.Errorf("expected %v, received: %v", expectedBytes, b.Data())
+	}
+}
+
+func TestEncoderBytes2(t *testing.T) {
+	t.Parallel()
+
+	b := buffer.New()
+	encoder := NewEncoder(b)
+
+	if err := encoder.Encode(Bytes2); err != nil {
+		t.Error("failed to encode byte array")
+	}
+
+	if string(b.Data()) != string(expectedBytes2) {
+		t.Errorf("expected %v, received: %v", expectedBytes2, b.Data())
+	}
+}
+
+func TestEncoderFloats(t *testing.T) {
+	t.Parallel()
+
+	b := buffer.New()
+	encoder := NewEncoder(b)
+
+	if err := encoder.Encode(Floats); err != nil {
+		t.Error("failed to encode floats")
+	}
+
+	if string(b.Data()) != string(expectedFloats) {
+		t.Errorf("expected %v, received: %v", expectedFloats, b.Data())
+	}
+}
+
 func TestEncoderStruct(t *testing.T) {
 	t.Parallel()
 
@@ -475,3 +537,45 @@ func TestEncoderInterfaceStructAll(t *testing.T) {
 		t.Errorf("expected: %v, received: %v", expectedInterfaceStructAll, b.Data())
 	}
 }
+
+func TestEncoderReset(t *testing.T) {
+	t.Parallel()
+
+	encoder := NewEncoder(buffer.New())
+
+	for i := 0; i < 2; i++ {
+		b := buffer.New()
+		encoder.Reset(b)
+
+		if err := encoder.Encode(Struct2); err != nil {
+			t.Error("failed to encode after reset")
+		}
+
+		if string(b.Data()) != string(expectedStruct) {
+			t.Errorf("expected %v, received: %v", expectedStruct, b.Data())
+		}
+	}
+}
+
+func TestEncoderContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := NewEncoder(buffer.New()).EncodeContext(ctx, StructAllValue); err != context.Canceled {
+		t.Errorf("expected %v, received: %v", context.Canceled, err)
+	}
+}
+
+func TestEncoderContextStream(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Nothing is ever sent, only the context ends the stream.
+	if err := NewEncoder(buffer.New(), Stream()).EncodeContext(ctx, make(chan int)); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, received: %v", context.DeadlineExceeded, err)
+	}
+}
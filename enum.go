@@ -0,0 +1,136 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Enums are still written as VarInts, names are only for reading values.
+
+// enumKey returns the integer of value, unsigned ones keep their bits.
+func enumKey(value reflect.Value) int64 {
+	switch value.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint())
+	default:
+		return value.Int()
+	}
+}
+
+// RegisterEnum panics when T is already registered.
+func RegisterEnum[T Integer](names map[T]string) {
+	m := make(map[int64]string, len(names))
+
+	for v, name := range names {
+		m[enumKey(reflect.ValueOf(v))] = name
+	}
+
+	if err := std.RegisterEnum(reflect.TypeFor[T](), m); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterEnum gives names to the values of t, unsigned values are keyed by their bits.
+func (codec *Codec) RegisterEnum(t reflect.Type, names map[int64]string) error {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return fmt.Errorf("%w: %v is not an integer", Invalid, t)
+	}
+
+	if _, loaded := codec.enums.LoadOrStore(t, names); loaded {
+		return fmt.Errorf("%w: %v", EnumExists, t)
+	}
+
+	return nil
+}
+
+// enum returns the name of value, if its type is an enum and if the value is defined.
+func (codec *Codec) enum(value reflect.Value) (string, bool, bool) {
+	names, ok := codec.enums.Load(value.Type())
+	if !ok {
+		return "", false, false
+	}
+
+	name, ok := names.(map[int64]string)[enumKey(value)]
+	return name, true, ok
+}
+
+// EnumName returns the name of v if its type is an enum and the value is defined.
+func EnumName(v interface{}) (string, bool) {
+	return std.EnumName(v)
+}
+
+// EnumName is the same as the package function using the enums of the codec.
+func (codec *Codec) EnumName(v interface{}) (string, bool) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return "", false
+	}
+
+	name, _, ok := codec.enum(value)
+	return name, ok
+}
+
+// enumNames returns the name of v when it has one, otherwise v.
+func (codec *Codec) enumNames(v interface{}) interface{} {
+	if name, ok := codec.EnumName(v); ok {
+		return name
+	}
+
+	return v
+}
+
+// defined returns UnknownEnum for enums, or arrays and slices of them, holding undefined values.
+func (decoder *Decoder) defined(value reflect.Value) error {
+	if !decoder.options.enums {
+		return nil
+	}
+
+	if value.Kind() == reflect.Array || value.Kind() == reflect.Slice {
+		if _, ok := decoder.codec.enums.Load(value.Type().Elem()); !ok {
+			return nil
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if err := decoder.defined(value.Index(i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if _, registered, ok := decoder.codec.enum(value); registered && !ok {
+		return fmt.Errorf("%w: %v of %v", UnknownEnum, value.Interface(), value.Type())
+	}
+
+	return nil
+}
+
+// StrictEnums makes decoding an undefined value of an enum return UnknownEnum.
+func StrictEnums() Option {
+	return func(o *options) {
+		o.enums = true
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type field struct {
+	index  int
+	tag    int
+	fixed  bool
+	packed bool
+	delta  bool
+	stream bool
+	oneof  string
+}
+
+// fields caches the tags of a struct type, so encoding
+// and decoding don't need to parse them again.
+type fields struct {
+	list    []field
+	tags    map[int]int
+	unknown int
+	oneofs  map[string][]int
+	err     error
+}
+
+var mfields sync.Map
+
+func typeFields(t reflect.Type) *fields {
+	if f, ok := mfields.Load(t); ok {
+		return f.(*fields)
+	}
+
+	f := &fields{
+		tags:    make(map[int]int),
+		unknown: -1,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+
+		if !ft.IsExported() {
+			continue
+		}
+
+		if ft.Type == reflect.TypeFor[Unknown]() {
+			f.unknown = i
+			continue
+		}
+
+		fi := field{
+			index: i,
+			tag:   i + 1,
+		}
+
+		if lookup, ok := ft.Tag.Lookup("bin"); ok {
+			if lookup == "-" {
+				continue
+			}
+
+			if err := fi.parse(lookup); err != nil {
+				if f.err == nil {
+					f.err = err
+				}
+
+				continue
+			}
+		}
+
+		if fi.oneof != "" {
+			if f.oneofs == nil {
+				f.oneofs = make(map[string][]int)
+			}
+
+			f.oneofs[fi.oneof] = append(f.oneofs[fi.oneof], len(f.list))
+		}
+
+		f.tags[fi.tag] = len(f.list)
+		f.list = append(f.list, fi)
+	}
+
+	actual, _ := mfields.LoadOrStore(t, f)
+	return actual.(*fields)
+}
+
+// parse reads a tag as `<number>,<option>...`, the number may be omitted to keep the default.
+func (f *field) parse(lookup string) error {
+	options := strings.Split(lookup, ",")
+
+	if options[0] != "" {
+		n, err := strconv.Atoi(options[0])
+		if err != nil {
+			return err
+		}
+
+		f.tag = n
+	}
+
+	for _, option := range options[1:] {
+		if name, ok := strings.CutPrefix(option, "oneof="); ok && name != "" {
+			f.oneof = name
+			continue
+		}
+
+		switch option {
+		case "fixed":
+			f.fixed = true
+		case "packed":
+			f.packed = true
+		case "delta":
+			f.delta = true
+		case "stream":
+			f.stream = true
+		default:
+			return UnknownOption
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Kinds used by interfaced floats and complexes when encoded as fixed words.
+const (
+	kindFixedFloat32 = 70 + iota
+	kindFixedFloat64
+	kindFixedComplex64
+	kindFixedComplex128
+)
+
+func (encoder *Encoder) kind(t reflect.Type) int {
+	if n, _ := encoder.codec.kinds.Load(t); n != 0 {
+		return n
+	}
+
+	if encoder.options.stream && t.Kind() == reflect.Chan {
+		return kindStream
+	}
+
+	if !encoder.options.fixed {
+		return int(t.Kind())
+	}
+
+	switch t.Kind() {
+	case reflect.Float32:
+		return kindFixedFloat32
+	case reflect.Float64:
+		return kindFixedFloat64
+	case reflect.Complex64:
+		return kindFixedComplex64
+	case reflect.Complex128:
+		return kindFixedComplex128
+	default:
+		return int(t.Kind())
+	}
+}
+
+func (encoder *Encoder) float(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Float32:
+		return encoder.bits32(math.Float32bits(float32(value.Float())))
+	case reflect.Float64:
+		return encoder.bits64(math.Float64bits(value.Float()))
+	case reflect.Complex64:
+		c := complex64(value.Complex())
+
+		if err := encoder.bits32(math.Float32bits(real(c))); err != nil {
+			return err
+		}
+
+		return encoder.bits32(math.Float32bits(imag(c)))
+	case reflect.Complex128:
+		c := value.Complex()
+
+		if err := encoder.bits64(math.Float64bits(real(c))); err != nil {
+			return err
+		}
+
+		return encoder.bits64(math.Float64bits(imag(c)))
+	default:
+		return Invalid
+	}
+}
+
+func (encoder *Encoder) bits32(bits uint32) error {
+	if encoder.options.fixed {
+		return writeFixed(encoder.writer, uint64(bits), 4)
+	}
+
+	return VarIntIn(encoder.writer, bits)
+}
+
+func (encoder *Encoder) bits64(bits uint64) error {
+	if encoder.options.fixed {
+		return writeFixed(encoder.writer, bits, 8)
+	}
+
+	return VarIntIn(encoder.writer, bits)
+}
+
+func (encoder *Encoder) append32(b []byte, bits uint32) []byte {
+	if encoder.options.fixed {
+		return binary.LittleEndian.AppendUint32(b, bits)
+	}
+
+	return appendVarInt(b, bits)
+}
+
+func (encoder *Encoder) append64(b []byte, bits uint64) []byte {
+	if encoder.options.fixed {
+		return binary.LittleEndian.AppendUint64(b, bits)
+	}
+
+	return appendVarInt(b, bits)
+}
+
+func (decoder *Decoder) float(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Float32:
+		bits, err := decoder.bits32()
+		if err != nil {
+			return err
+		}
+
+		value.SetFloat(floatFromBits(bits))
+		return nil
+	case reflect.Float64:
+		bits, err := decoder.bits64()
+		if err != nil {
+			return err
+		}
+
+		value.SetFloat(floatFromBits(bits))
+		return nil
+	case reflect.Complex64:
+		r, err := decoder.bits32()
+		if err != nil {
+			return err
+		}
+
+		i, err := decoder.bits32()
+		if err != nil {
+			return err
+		}
+
+		value.SetComplex(complex(floatFromBits(r), floatFromBits(i)))
+		return nil
+	case reflect.Complex128:
+		r, err := decoder.bits64()
+		if err != nil {
+			return err
+		}
+
+		i, err := decoder.bits64()
+		if err != nil {
+			return err
+		}
+
+		value.SetComplex(complex(floatFromBits(r), floatFromBits(i)))
+		return nil
+	default:
+		return Invalid
+	}
+}
+
+func (decoder *Decoder) bits32() (uint32, error) {
+	if decoder.options.fixed {
+		bits, err := readFixed(decoder.reader, 4)
+		return uint32(bits), err
+	}
+
+	return VarIntOut[uint32](decoder.reader)
+}
+
+func (decoder *Decoder) bits64() (uint64, error) {
+	if decoder.options.fixed {
+		return readFixed(decoder.reader, 8)
+	}
+
+	return VarIntOut[uint64](decoder.reader)
+}
+
+// writeFixed writes the first size bytes of v in little endian.
+func writeFixed(writer io.Writer, v uint64, size int) error {
+	if bw, ok := writer.(io.ByteWriter); ok {
+		for i := 0; i < size; i++ {
+			if err := bw.WriteByte(byte(v >> (8 * i))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	b := make([]byte, size)
+
+	for i := 0; i < size; i++ {
+		b[i] = byte(v >> (8 * i))
+	}
+
+	_, err := writer.Write(b)
+	return err
+}
+
+func readFixed(reader io.Reader, size int) (uint64, error) {
+	var v uint64
+
+	for i := 0; i < size; i++ {
+		b, err := readByte(reader)
+		if err != nil {
+			return 0, err
+		}
+
+		v |= uint64(b) << (8 * i)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,63 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Kind used by interfaced implementations, followed by their id and the value as its type.
+const kindImpl = 88
+
+// RegisterImpl panics when I is not an interface, T does not implement I or either the id or T is already registered.
+func RegisterImpl[I, T interface{}](id int) {
+	if err := std.RegisterImpl(id, reflect.TypeFor[I](), reflect.TypeFor[T]()); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterImpl makes t be decoded into fields, slices and maps of the interface i, ids are shared by every interface
+// of the codec. Values of t are matched without their pointers but decoded as t was given.
+func (codec *Codec) RegisterImpl(id int, i, t reflect.Type) error {
+	if i.Kind() != reflect.Interface || !t.Implements(i) {
+		return fmt.Errorf("%w: %v is not %v", NotImplemented, t, i)
+	}
+
+	return codec.impls.store(id, t, ImplExists)
+}
+
+// impl writes an implementation, returning false if its type is not registered.
+func (encoder *Encoder) impl(value reflect.Value) (bool, error) {
+	id, ok := encoder.codec.impls.key(value.Type())
+	if !ok {
+		return false, nil
+	}
+
+	if err := VarIntIn(encoder.writer, kindImpl); err != nil {
+		return true, err
+	}
+
+	if err := VarIntIn(encoder.writer, id.(int)); err != nil {
+		return true, err
+	}
+
+	return true, encoder.encode(value)
+}
@@ -24,28 +24,37 @@ import (
 )
 
 func Interface(v interface{}) reflect.Value {
+	return std.Interface(v)
+}
+
+// Interface is the same as the package function using the kinds of the codec.
+func (codec *Codec) Interface(v interface{}) reflect.Value {
 	if v == nil {
 		return reflect.New(reflect.TypeFor[interface{}]()).Elem()
 	}
 
 	ptr := reflect.New(reflect.TypeFor[interface{}]()).Elem()
-	if n, _ := mkind.Load(Value(v).Type()); n != 0 {
+	if n, _ := codec.kinds.Load(Value(v).Type()); n != 0 {
 		ptr.Set(Value(v))
 	} else {
-		ptr.Set(interfaces(Value(v)))
+		ptr.Set(interfaces(codec, Value(v)))
 	}
 
 	return ptr
 }
 
-func interfaces(value reflect.Value) reflect.Value {
+func interfaces(codec *Codec, value reflect.Value) reflect.Value {
+	if value.Type() == reflect.TypeFor[Struct]() || codec.named(value.Type()) || registered(codec.kinds, value.Type()) {
+		return value.Convert(reflect.TypeFor[interface{}]())
+	}
+
 	switch value.Kind() {
 	case reflect.Array:
-		if _, elem := KeyElem(value); Abs[reflect.Type](elem).Kind() == reflect.Struct {
+		if _, elem := KeyElem(value); isStruct(codec.kinds, elem) {
 			ptr := reflect.New(reflect.ArrayOf(value.Len(), reflect.TypeFor[interface{}]())).Elem()
 
 			for i := 0; i < value.Len(); i++ {
-				ptr.Index(i).Set(interfaces(value.Index(i)))
+				ptr.Index(i).Set(interfaces(codec, value.Index(i)))
 			}
 
 			return ptr.Convert(reflect.TypeFor[interface{}]())
@@ -53,11 +62,11 @@ func interfaces(value reflect.Value) reflect.Value {
 
 		return value.Convert(reflect.TypeFor[interface{}]())
 	case reflect.Slice:
-		if _, elem := KeyElem(value); Abs[reflect.Type](elem).Kind() == reflect.Struct {
+		if _, elem := KeyElem(value); isStruct(codec.kinds, elem) {
 			ptr := reflect.MakeSlice(reflect.TypeFor[[]interface{}](), value.Len(), value.Cap())
 
 			for i := 0; i < value.Len(); i++ {
-				ptr.Index(i).Set(interfaces(value.Index(i)))
+				ptr.Index(i).Set(interfaces(codec, value.Index(i)))
 			}
 
 			return ptr.Convert(reflect.TypeFor[interface{}]())
@@ -67,8 +76,8 @@ func interfaces(value reflect.Value) reflect.Value {
 	case reflect.Map:
 		kt, vt := KeyElem(value)
 
-		kb := Abs[reflect.Type](kt).Kind() == reflect.Struct
-		vb := Abs[reflect.Type](vt).Kind() == reflect.Struct
+		kb := isStruct(codec.kinds, kt)
+		vb := isStruct(codec.kinds, vt)
 
 		if !kb && !vb {
 			return value.Convert(reflect.TypeFor[interface{}]())
@@ -82,11 +91,11 @@ func interfaces(value reflect.Value) reflect.Value {
 			k, v := m.Key(), m.Value()
 
 			if kb {
-				k = interfaces(k)
+				k = interfaces(codec, k)
 			}
 
 			if vb {
-				v = interfaces(v)
+				v = interfaces(codec, v)
 			}
 
 			ptr.SetMapIndex(k, v)
@@ -118,8 +127,8 @@ func interfaces(value reflect.Value) reflect.Value {
 		tmp := reflect.New(reflect.StructOf(fields)).Elem()
 
 		for i, v := range values {
-			if v.Kind() == reflect.Struct {
-				v = interfaces(v)
+			if v.Kind() == reflect.Struct && !registered(codec.kinds, v.Type()) {
+				v = interfaces(codec, v)
 			}
 
 			tmp.Field(i).Set(v)
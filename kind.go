@@ -26,22 +26,61 @@ import (
 
 var mkind = &kind.Map{}
 
+// builtins keeps the kinds of this package apart from registered ones, new codecs start with them.
+var builtins = &kind.Map{}
+
+// Register panics when the kind is out of range or either the kind or type is already registered.
 func Register[T interface{}](n int, handler kind.Handler) {
-	if n < 128 {
-		panic("invalid kind range")
+	if err := std.Register(n, reflect.TypeFor[T](), handler); err != nil {
+		panic(err)
 	}
-
-	register(n, Abs[reflect.Type](reflect.TypeFor[T]()), handler)
 }
 
 func register(n int, t reflect.Type, handler kind.Handler) {
-	mkind.Store(n, t, handler)
+	for _, m := range []*kind.Map{mkind, builtins} {
+		if err := m.Store(n, t, handler); err != nil {
+			panic(err)
+		}
+	}
 }
 
+// Alias panics when the kind is out of range or unknown, or the type is already registered.
 func Alias[T interface{}](n int) {
-	if n < 128 {
-		panic("invalid kind range")
+	if err := std.Alias(n, reflect.TypeFor[T]()); err != nil {
+		panic(err)
+	}
+}
+
+func alias(n int, t reflect.Type) {
+	for _, m := range []*kind.Map{mkind, builtins} {
+		if err := m.Alias(n, t); err != nil {
+			panic(err)
+		}
 	}
+}
+
+// Unregister removes a kind with its aliases, kinds of this package included.
+func Unregister(n int) error {
+	return std.Unregister(n)
+}
+
+// Registered lists every kind and alias of the package functions.
+func Registered() []kind.Data {
+	return std.Registered()
+}
+
+func registered(kinds *kind.Map, t reflect.Type) bool {
+	n, _ := kinds.Load(t)
+	return n != 0
+}
+
+// isStruct reports if t is a struct handled as fields, registered structs are handled by their kind.
+func isStruct(kinds *kind.Map, t reflect.Type) bool {
+	t = Abs[reflect.Type](t)
+	return t.Kind() == reflect.Struct && !registered(kinds, t)
+}
 
-	mkind.Alias(n, Abs[reflect.Type](reflect.TypeFor[T]()))
+// fielded reports if t is a struct decoded by its fields, a zero one written as a single zero was never set.
+func fielded(kinds *kind.Map, t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeFor[Struct]() && !registered(kinds, t)
 }
@@ -20,22 +20,46 @@
 package kind
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 )
 
+var (
+	KindExists  = errors.New("kind already registered")
+	TypeExists  = errors.New("type already registered")
+	UnknownKind = errors.New("unknown kind")
+)
+
 type Data struct {
 	Kind    int
 	Type    reflect.Type
 	Handler Handler
 }
 
+// Map holds kinds by number and by type, mtype caches lookups including the ones not found
+// and is cleared whenever kinds or aliases change.
 type Map struct {
-	mkind sync.Map
-	mtype sync.Map
+	mu     sync.Mutex
+	mkind  sync.Map
+	malias sync.Map
+	mtype  sync.Map
 }
 
-func (m *Map) Store(kind int, t reflect.Type, handler Handler) {
+func (m *Map) Store(kind int, t reflect.Type, handler Handler) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if data, ok := m.mkind.Load(kind); ok {
+		return fmt.Errorf("%w: %d is %v", KindExists, kind, data.(*Data).Type)
+	}
+
+	if data, ok := m.malias.Load(t); ok {
+		return fmt.Errorf("%w: %v is %d", TypeExists, t, data.(*Data).Kind)
+	}
+
 	data := &Data{
 		Kind:    kind,
 		Type:    t,
@@ -43,7 +67,10 @@ func (m *Map) Store(kind int, t reflect.Type, handler Handler) {
 	}
 
 	m.mkind.Store(kind, data)
-	m.mtype.Store(t, data)
+	m.malias.Store(t, data)
+	m.mtype.Clear()
+
+	return nil
 }
 
 func (m *Map) load(v interface{}) *Data {
@@ -63,27 +90,10 @@ func (m *Map) load(v interface{}) *Data {
 	case reflect.Type:
 		t, ok := m.mtype.Load(v)
 		if !ok {
-			p := reflect.PointerTo(v)
-
-			m.mtype.Range(func(rk, rv any) bool {
-				if rk.(reflect.Type).Kind() != reflect.Interface {
-					return true
-				}
-
-				if v.Implements(rk.(reflect.Type)) || p.Implements(rk.(reflect.Type)) {
-					t = rv
-					return false
-				}
-
-				return true
-			})
-
-			if t == nil {
-				m.mtype.Store(v, true)
-				return nil
-			}
-
+			m.mu.Lock()
+			t = m.lookup(v)
 			m.mtype.Store(v, t)
+			m.mu.Unlock()
 		}
 
 		switch t := t.(type) {
@@ -99,6 +109,47 @@ func (m *Map) load(v interface{}) *Data {
 	}
 }
 
+// lookup finds the kind of a type by itself or by an interface it implements, false when there is none.
+func (m *Map) lookup(v reflect.Type) interface{} {
+	if data, ok := m.malias.Load(v); ok {
+		return data
+	}
+
+	if m.pointer(v) {
+		return false
+	}
+
+	var t interface{} = false
+	p := reflect.PointerTo(v)
+
+	m.malias.Range(func(rk, rv any) bool {
+		if rk.(reflect.Type).Kind() != reflect.Interface {
+			return true
+		}
+
+		if v.Implements(rk.(reflect.Type)) || p.Implements(rk.(reflect.Type)) {
+			t = rv
+			return false
+		}
+
+		return true
+	})
+
+	return t
+}
+
+// pointer reports if t points to a registered type, those are dereferenced
+// before being handled so they don't match an interface first.
+func (m *Map) pointer(t reflect.Type) bool {
+	for ; t.Kind() == reflect.Pointer; t = t.Elem() {
+		if _, ok := m.malias.Load(t.Elem()); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (m *Map) Load(v interface{}) (int, reflect.Type) {
 	data := m.load(v)
 	if data == nil {
@@ -108,13 +159,88 @@ func (m *Map) Load(v interface{}) (int, reflect.Type) {
 	return data.Kind, data.Type
 }
 
-func (m *Map) Alias(kind int, t reflect.Type) {
+func (m *Map) Alias(kind int, t reflect.Type) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	data, ok := m.mkind.Load(kind)
 	if !ok {
-		return
+		return fmt.Errorf("%w: %d", UnknownKind, kind)
+	}
+
+	if data, ok := m.malias.Load(t); ok {
+		return fmt.Errorf("%w: %v is %d", TypeExists, t, data.(*Data).Kind)
 	}
 
-	m.mtype.Store(t, data)
+	m.malias.Store(t, data)
+	m.mtype.Clear()
+
+	return nil
+}
+
+// Unregister removes a kind with its aliases.
+func (m *Map) Unregister(kind int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.mkind.LoadAndDelete(kind)
+	if !ok {
+		return fmt.Errorf("%w: %d", UnknownKind, kind)
+	}
+
+	m.malias.Range(func(k, v any) bool {
+		if v == data {
+			m.malias.Delete(k)
+		}
+
+		return true
+	})
+
+	m.mtype.Clear()
+	return nil
+}
+
+// Registered lists every kind and alias sorted by kind, aliases have the type they were aliased with.
+func (m *Map) Registered() []Data {
+	var list []Data
+
+	m.malias.Range(func(k, v any) bool {
+		data := *v.(*Data)
+		data.Type = k.(reflect.Type)
+
+		list = append(list, data)
+		return true
+	})
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+
+		return list[i].Type.String() < list[j].Type.String()
+	})
+
+	return list
+}
+
+// Clone returns a copy of the map, later changes to either map don't affect the other.
+func (m *Map) Clone() *Map {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := &Map{}
+
+	m.mkind.Range(func(k, v any) bool {
+		c.mkind.Store(k, v)
+		return true
+	})
+
+	m.malias.Range(func(k, v any) bool {
+		c.malias.Store(k, v)
+		return true
+	})
+
+	return c
 }
 
 func (m *Map) Run(v, i interface{}, value reflect.Value) (bool, error) {
@@ -22,11 +22,26 @@
 package bin
 
 import (
+	"fmt"
 	"github.com/Dviih/bin/kind"
+	"io"
 	"math/big"
 	"reflect"
 )
 
+// Floats and rats start with a version, any other number is the size of their gob.
+const bigNative = 1
+
+// bigGob is the largest gob read from older versions.
+const bigGob = 1 << 24
+
+// Forms of a float.
+const (
+	floatZero = iota
+	floatFinite
+	floatInf
+)
+
 func init() {
 	i := kind.NewHandler(
 		func(encoder kind.Encoder, value reflect.Value) error {
@@ -44,7 +59,176 @@ func init() {
 		},
 	)
 
+	f := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			x := kind.Pointer(value).Interface().(*big.Float)
+
+			form := floatFinite
+
+			switch {
+			case x.Sign() == 0:
+				form = floatZero
+			case x.IsInf():
+				form = floatInf
+			}
+
+			for _, v := range []interface{}{bigNative, uint64(x.Prec()), uint64(x.Mode()), form, x.Signbit()} {
+				if err := encoder.Encode(v); err != nil {
+					return err
+				}
+			}
+
+			if form != floatFinite {
+				return nil
+			}
+
+			mant := new(big.Float)
+			exp := x.MantExp(mant)
+
+			// The mantissa becomes an integer with as many bits as needed.
+			m, _ := mant.Abs(mant).SetMantExp(mant, int(x.MinPrec())).Int(nil)
+
+			if err := encoder.Encode(zigzag(int64(exp))); err != nil {
+				return err
+			}
+
+			return encoder.Encode(m.Bytes())
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			version, err := bigVersion(decoder, value)
+			if err != nil || version != bigNative {
+				return err
+			}
+
+			var prec, mode uint64
+			var form int
+			var neg bool
+
+			for _, v := range []interface{}{&prec, &mode, &form, &neg} {
+				if err = decoder.Decode(v); err != nil {
+					return err
+				}
+			}
+
+			x := new(big.Float).SetPrec(uint(prec)).SetMode(big.RoundingMode(mode))
+
+			switch form {
+			case floatZero:
+			case floatFinite:
+				var exp uint64
+				var data []byte
+
+				if err = decoder.Decode(&exp); err != nil {
+					return err
+				}
+
+				if err = decoder.Decode(&data); err != nil {
+					return err
+				}
+
+				m := new(big.Int).SetBytes(data)
+				x.SetMantExp(x.SetInt(m), int(unzigzag(exp))-m.BitLen())
+			case floatInf:
+				x.SetInf(false)
+			default:
+				return Invalid
+			}
+
+			if neg {
+				x.Neg(x)
+			}
+
+			value.Set(reflect.ValueOf(x).Elem())
+			return nil
+		},
+	)
+
+	r := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			x := kind.Pointer(value).Interface().(*big.Rat)
+
+			for _, v := range []interface{}{bigNative, x.Sign() < 0, x.Num().Bytes(), x.Denom().Bytes()} {
+				if err := encoder.Encode(v); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			version, err := bigVersion(decoder, value)
+			if err != nil || version != bigNative {
+				return err
+			}
+
+			var neg bool
+			var num, denom []byte
+
+			for _, v := range []interface{}{&neg, &num, &denom} {
+				if err = decoder.Decode(v); err != nil {
+					return err
+				}
+			}
+
+			a := new(big.Int).SetBytes(num)
+			if neg {
+				a.Neg(a)
+			}
+
+			b := new(big.Int).SetBytes(denom)
+			if b.Sign() == 0 {
+				return Invalid
+			}
+
+			value.Set(reflect.ValueOf(new(big.Rat).SetFrac(a, b)).Elem())
+			return nil
+		},
+	)
+
 	register(67, reflect.TypeFor[big.Int](), i)
-	register(68, reflect.TypeFor[big.Float](), kind.Gob)
-	register(69, reflect.TypeFor[big.Rat](), kind.Gob)
+	register(68, reflect.TypeFor[big.Float](), f)
+	register(69, reflect.TypeFor[big.Rat](), r)
+}
+
+// bigVersion reads the version of a float or rat, a gob is decoded right away
+// and zero sets the zero value, both returning 0 as there is nothing else to read.
+func bigVersion(decoder kind.Decoder, value reflect.Value) (int, error) {
+	var n int
+
+	if err := decoder.Decode(&n); err != nil {
+		return 0, err
+	}
+
+	switch n {
+	case 0:
+		value.SetZero()
+		return 0, nil
+	case bigNative:
+		return n, nil
+	}
+
+	if n < 2 || n > bigGob {
+		return 0, fmt.Errorf("%w: gob of %d bytes", Invalid, n)
+	}
+
+	d, ok := decoder.(*Decoder)
+	if !ok {
+		return 0, Invalid
+	}
+
+	// Reading as it arrives doesn't allocate the whole size for a short input.
+	data, err := io.ReadAll(io.LimitReader(d.reader, int64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) != n {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	if out := kind.Call(value, "GobDecode", reflect.ValueOf(data)); !out[0].IsNil() {
+		return 0, out[0].Interface().(error)
+	}
+
+	return 0, nil
 }
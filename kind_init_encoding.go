@@ -29,5 +29,5 @@ import (
 
 func init() {
 	register(65, reflect.TypeFor[encoding.BinaryMarshaler](), kind.EncodingBinary)
-	mkind.Alias(65, reflect.TypeFor[encoding.BinaryUnmarshaler]())
+	alias(65, reflect.TypeFor[encoding.BinaryUnmarshaler]())
 }
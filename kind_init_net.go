@@ -0,0 +1,226 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+//go:build !dviih_bin_kind_net
+
+package bin
+
+import (
+	"github.com/Dviih/bin/kind"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+func init() {
+	addr := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			return encodeAddr(encoder, value.Interface().(netip.Addr))
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			addr, err := decodeAddr(decoder)
+			if err != nil {
+				return err
+			}
+
+			value.Set(reflect.ValueOf(addr))
+			return nil
+		},
+	)
+
+	addrPort := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			addrPort := value.Interface().(netip.AddrPort)
+
+			if err := encodeAddr(encoder, addrPort.Addr()); err != nil {
+				return err
+			}
+
+			if !addrPort.Addr().IsValid() {
+				return nil
+			}
+
+			return encoder.Encode(addrPort.Port())
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			addr, err := decodeAddr(decoder)
+			if err != nil {
+				return err
+			}
+
+			var port uint16
+
+			if addr.IsValid() {
+				if err = decoder.Decode(&port); err != nil {
+					return err
+				}
+			}
+
+			value.Set(reflect.ValueOf(netip.AddrPortFrom(addr, port)))
+			return nil
+		},
+	)
+
+	prefix := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			prefix := value.Interface().(netip.Prefix)
+
+			if err := encodeAddr(encoder, prefix.Addr()); err != nil {
+				return err
+			}
+
+			if !prefix.Addr().IsValid() {
+				return nil
+			}
+
+			return encoder.Encode(prefix.Bits())
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			addr, err := decodeAddr(decoder)
+			if err != nil {
+				return err
+			}
+
+			if !addr.IsValid() {
+				value.Set(reflect.ValueOf(netip.Prefix{}))
+				return nil
+			}
+
+			var bits int
+
+			if err = decoder.Decode(&bits); err != nil {
+				return err
+			}
+
+			value.Set(reflect.ValueOf(netip.PrefixFrom(addr, bits)))
+			return nil
+		},
+	)
+
+	// IPs and hardware addresses are kept as they are, only the kind tells them apart from bytes.
+	b := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			return encoder.Encode(value.Bytes())
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var data []byte
+
+			if err := decoder.Decode(&data); err != nil {
+				return err
+			}
+
+			if len(data) == 0 {
+				data = nil
+			}
+
+			value.SetBytes(data)
+			return nil
+		},
+	)
+
+	u := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			return encoder.Encode(kind.Call(value, "String")[0].Interface())
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var s string
+
+			if err := decoder.Decode(&s); err != nil {
+				return err
+			}
+
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+
+			value.Set(reflect.ValueOf(u).Elem())
+			return nil
+		},
+	)
+
+	r := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			return encoder.Encode(kind.Call(value, "String")[0].Interface())
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var s string
+
+			if err := decoder.Decode(&s); err != nil {
+				return err
+			}
+
+			r, err := regexp.Compile(s)
+			if err != nil {
+				return err
+			}
+
+			value.Set(reflect.ValueOf(r).Elem())
+			return nil
+		},
+	)
+
+	register(79, reflect.TypeFor[netip.Addr](), addr)
+	register(80, reflect.TypeFor[netip.AddrPort](), addrPort)
+	register(81, reflect.TypeFor[netip.Prefix](), prefix)
+	register(82, reflect.TypeFor[net.IP](), b)
+	register(83, reflect.TypeFor[net.HardwareAddr](), b)
+	register(84, reflect.TypeFor[url.URL](), u)
+	register(85, reflect.TypeFor[regexp.Regexp](), r)
+}
+
+// encodeAddr writes the 4 or 16 bytes of an address, IPv6 addresses are followed by their zone.
+func encodeAddr(encoder kind.Encoder, addr netip.Addr) error {
+	if err := encoder.Encode(addr.AsSlice()); err != nil {
+		return err
+	}
+
+	if !addr.Is6() {
+		return nil
+	}
+
+	return encoder.Encode(addr.Zone())
+}
+
+func decodeAddr(decoder kind.Decoder) (netip.Addr, error) {
+	var data []byte
+
+	if err := decoder.Decode(&data); err != nil {
+		return netip.Addr{}, err
+	}
+
+	switch len(data) {
+	case 0:
+		return netip.Addr{}, nil
+	case 4:
+		return netip.AddrFrom4([4]byte(data)), nil
+	case 16:
+		var zone string
+
+		if err := decoder.Decode(&zone); err != nil {
+			return netip.Addr{}, err
+		}
+
+		return netip.AddrFrom16([16]byte(data)).WithZone(zone), nil
+	default:
+		return netip.Addr{}, Invalid
+	}
+}
@@ -0,0 +1,254 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+//go:build !dviih_bin_kind_time
+
+package bin
+
+import (
+	"fmt"
+	"github.com/Dviih/bin/kind"
+	"io"
+	"reflect"
+	"time"
+)
+
+// Zones of a time, the zero time is only the zone.
+const (
+	zoneZero = iota
+	zoneUTC
+	zoneOffset
+	zoneName
+)
+
+// timeBinary is the largest time.MarshalBinary, times written with kind 65 start with its length instead of a zone.
+const timeBinary = 16
+
+func init() {
+	t := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			t := value.Interface().(time.Time)
+
+			if t.IsZero() {
+				return encoder.Encode(zoneZero)
+			}
+
+			zone := zoneName
+
+			switch {
+			case t.Location() == time.UTC:
+				zone = zoneUTC
+			case t.Location().String() == "":
+				zone = zoneOffset
+			}
+
+			if err := encoder.Encode(zone); err != nil {
+				return err
+			}
+
+			if err := encoder.Encode(zigzag(t.Unix())); err != nil {
+				return err
+			}
+
+			if err := encoder.Encode(uint64(t.Nanosecond())); err != nil {
+				return err
+			}
+
+			name, offset := t.Zone()
+
+			switch zone {
+			case zoneOffset:
+				return encoder.Encode(zigzag(int64(offset)))
+			case zoneName:
+				if err := encoder.Encode(t.Location().String()); err != nil {
+					return err
+				}
+
+				if err := encoder.Encode(name); err != nil {
+					return err
+				}
+
+				return encoder.Encode(zigzag(int64(offset)))
+			default:
+				return nil
+			}
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var zone int
+
+			if err := decoder.Decode(&zone); err != nil {
+				return err
+			}
+
+			if zone == zoneZero {
+				value.Set(reflect.ValueOf(time.Time{}))
+				return nil
+			}
+
+			if zone > zoneName {
+				return timeLegacy(decoder, value, zone)
+			}
+
+			var sec, nsec uint64
+
+			if err := decoder.Decode(&sec); err != nil {
+				return err
+			}
+
+			if err := decoder.Decode(&nsec); err != nil {
+				return err
+			}
+
+			t := time.Unix(unzigzag(sec), int64(nsec))
+
+			switch zone {
+			case zoneUTC:
+				t = t.UTC()
+			case zoneOffset:
+				var offset uint64
+
+				if err := decoder.Decode(&offset); err != nil {
+					return err
+				}
+
+				t = t.In(time.FixedZone("", int(unzigzag(offset))))
+			case zoneName:
+				var location, name string
+				var offset uint64
+
+				if err := decoder.Decode(&location); err != nil {
+					return err
+				}
+
+				if err := decoder.Decode(&name); err != nil {
+					return err
+				}
+
+				if err := decoder.Decode(&offset); err != nil {
+					return err
+				}
+
+				t = t.In(zoneLocation(t, location, name, int(unzigzag(offset))))
+			default:
+				return Invalid
+			}
+
+			value.Set(reflect.ValueOf(t))
+			return nil
+		},
+	)
+
+	d := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			return encoder.Encode(zigzag(value.Int()))
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var n uint64
+
+			if err := decoder.Decode(&n); err != nil {
+				return err
+			}
+
+			value.SetInt(unzigzag(n))
+			return nil
+		},
+	)
+
+	l := kind.NewHandler(
+		func(encoder kind.Encoder, value reflect.Value) error {
+			loc := value.Interface().(time.Location)
+			name, offset := time.Unix(0, 0).In(&loc).Zone()
+
+			if err := encoder.Encode(loc.String()); err != nil {
+				return err
+			}
+
+			if err := encoder.Encode(name); err != nil {
+				return err
+			}
+
+			return encoder.Encode(zigzag(int64(offset)))
+		},
+		func(decoder kind.Decoder, value reflect.Value) error {
+			var location, name string
+			var offset uint64
+
+			if err := decoder.Decode(&location); err != nil {
+				return err
+			}
+
+			if err := decoder.Decode(&name); err != nil {
+				return err
+			}
+
+			if err := decoder.Decode(&offset); err != nil {
+				return err
+			}
+
+			loc := zoneLocation(time.Unix(0, 0), location, name, int(unzigzag(offset)))
+
+			value.Set(reflect.ValueOf(loc).Elem())
+			return nil
+		},
+	)
+
+	register(76, reflect.TypeFor[time.Time](), t)
+	register(77, reflect.TypeFor[time.Duration](), d)
+	register(78, reflect.TypeFor[time.Location](), l)
+}
+
+// zoneLocation loads a location by its name, when it is unknown
+// or disagrees with the offset the zone is kept as a fixed one.
+func zoneLocation(t time.Time, location, name string, offset int) *time.Location {
+	loc, err := time.LoadLocation(location)
+	if err == nil {
+		if _, o := t.In(loc).Zone(); o == offset {
+			return loc
+		}
+	}
+
+	return time.FixedZone(name, offset)
+}
+
+// timeLegacy decodes a time written by kind 65 as the n bytes of its MarshalBinary.
+func timeLegacy(decoder kind.Decoder, value reflect.Value, n int) error {
+	if n > timeBinary {
+		return fmt.Errorf("%w: time of %d bytes", Invalid, n)
+	}
+
+	d, ok := decoder.(*Decoder)
+	if !ok {
+		return Invalid
+	}
+
+	data := make([]byte, n)
+
+	if _, err := io.ReadFull(d.reader, data); err != nil {
+		return err
+	}
+
+	var t time.Time
+
+	if err := t.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	value.Set(reflect.ValueOf(t))
+	return nil
+}
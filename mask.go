@@ -0,0 +1,93 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FieldMask is a tree of tags, a nil FieldMask includes every field.
+type FieldMask map[int]FieldMask
+
+// NewFieldMask takes tag paths such as "20" and "50.3", a path includes every field under it.
+func NewFieldMask(paths ...string) (FieldMask, error) {
+	mask := FieldMask{}
+
+	for _, path := range paths {
+		list := segments(path)
+		if len(list) == 0 {
+			return nil, fmt.Errorf("%w: empty mask", InvalidPath)
+		}
+
+		m := mask
+
+		for i, segment := range list {
+			tag, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q is not a tag", InvalidPath, segment)
+			}
+
+			sub, ok := m[tag]
+
+			switch {
+			case i == len(list)-1:
+				m[tag] = nil
+			case ok && sub == nil:
+				// A shorter path already includes it.
+			default:
+				if !ok {
+					sub = FieldMask{}
+					m[tag] = sub
+				}
+
+				m = sub
+				continue
+			}
+
+			break
+		}
+	}
+
+	return mask, nil
+}
+
+// field returns the mask for what is under tag and if tag is included.
+func (mask FieldMask) field(tag int) (FieldMask, bool) {
+	if mask == nil {
+		return nil, true
+	}
+
+	sub, ok := mask[tag]
+	return sub, ok
+}
+
+// Masked encodes only the fields of mask, the rest are written as zero values,
+// decoding only sets the fields of mask and skips the rest.
+func Masked(mask FieldMask) Option {
+	return func(o *options) {
+		o.mask = mask
+	}
+}
+
+// MarshalMask encodes only the fields of mask.
+func MarshalMask(v interface{}, mask FieldMask) ([]byte, error) {
+	return std.MarshalMask(v, mask)
+}
@@ -0,0 +1,136 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"fmt"
+	"github.com/Dviih/bin/kind"
+	"reflect"
+	"sync"
+)
+
+// Kind used by interfaced values of a named type, followed by the name and the value as its type.
+const kindNamed = 87
+
+// Kind used by interfaced values of a type named by an id, followed by the id and the value as its type.
+const kindNamedID = 90
+
+// registry maps names or ids to the types they were registered with and types without pointers back to them.
+type registry struct {
+	mu    sync.Mutex
+	mkey  sync.Map
+	mtype sync.Map
+}
+
+func (r *registry) store(key interface{}, t reflect.Type, exists error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.mkey.Load(key); ok {
+		return fmt.Errorf("%w: %v is %v", exists, key, v)
+	}
+
+	if v, ok := r.mtype.Load(Abs[reflect.Type](t)); ok {
+		return fmt.Errorf("%w: %v is %v", kind.TypeExists, t, v)
+	}
+
+	r.mkey.Store(key, t)
+	r.mtype.Store(Abs[reflect.Type](t), key)
+
+	return nil
+}
+
+func (r *registry) key(t reflect.Type) (interface{}, bool) {
+	return r.mtype.Load(Abs[reflect.Type](t))
+}
+
+func (r *registry) load(key interface{}) (reflect.Type, bool) {
+	v, ok := r.mkey.Load(key)
+	if !ok {
+		return nil, false
+	}
+
+	return v.(reflect.Type), true
+}
+
+// RegisterName panics when either the name or type is already registered.
+func RegisterName[T interface{}](name string) {
+	if err := std.RegisterName(name, reflect.TypeFor[T]()); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterName makes interfaced values of t be decoded as t instead of a Struct, values of t are
+// matched without their pointers but decoded as t was given.
+func (codec *Codec) RegisterName(name string, t reflect.Type) error {
+	return codec.names.store(name, t, NameExists)
+}
+
+// RegisterNameID panics when either the id or type is already registered.
+func RegisterNameID[T interface{}](id int) {
+	if err := std.RegisterNameID(id, reflect.TypeFor[T]()); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterNameID is the same as RegisterName writing id instead of a name, ids and names are registered together
+// so a type has either of them.
+func (codec *Codec) RegisterNameID(id int, t reflect.Type) error {
+	return codec.names.store(id, t, NameExists)
+}
+
+// named writes a value of a named type, returning false if its type has no name.
+func (encoder *Encoder) named(value reflect.Value) (bool, error) {
+	key, ok := encoder.codec.names.key(value.Type())
+	if !ok {
+		return false, nil
+	}
+
+	switch key := key.(type) {
+	case int:
+		if err := VarIntIn(encoder.writer, kindNamedID); err != nil {
+			return true, err
+		}
+
+		if err := VarIntIn(encoder.writer, key); err != nil {
+			return true, err
+		}
+	default:
+		if err := VarIntIn(encoder.writer, kindNamed); err != nil {
+			return true, err
+		}
+
+		if err := encoder.Encode(key.(string)); err != nil {
+			return true, err
+		}
+	}
+
+	return true, encoder.encode(value)
+}
+
+// named reports if t has a name or is an implementation, those are encoded as their type.
+func (codec *Codec) named(t reflect.Type) bool {
+	if _, ok := codec.names.key(t); ok {
+		return true
+	}
+
+	_, ok := codec.impls.key(t)
+	return ok
+}
@@ -0,0 +1,106 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Fields sharing `oneof=<name>` in their tag form a union, at most one of them may be set.
+
+// oneof returns OneOfConflict if a union of value has more than one field set.
+func (fields *fields) oneof(value reflect.Value) error {
+	for name, list := range fields.oneofs {
+		set := 0
+
+		for _, i := range list {
+			if !value.Field(fields.list[i].index).IsZero() {
+				set++
+			}
+		}
+
+		if set > 1 {
+			return fmt.Errorf("%w: %s of %v", OneOfConflict, name, value.Type())
+		}
+	}
+
+	return nil
+}
+
+// member writes a pointer of a union as a marker, 1 if it is set followed by its value and 0 if it is nil,
+// so a pointer to a zero value is still told apart from nil.
+func (encoder *Encoder) member(field reflect.Value, kind bool) error {
+	if field.IsNil() {
+		return encoder.writeByte(0)
+	}
+
+	if err := encoder.writeByte(1); err != nil {
+		return err
+	}
+
+	return encoder.field(field.Elem(), kind)
+}
+
+func (decoder *Decoder) member(field reflect.Value) error {
+	b, err := readByte(decoder.reader)
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		field.SetZero()
+		return nil
+	case 1:
+		ptr := reflect.New(field.Type().Elem())
+
+		if err = decoder.decode(ptr.Elem()); err != nil {
+			return err
+		}
+
+		field.Set(ptr)
+		return nil
+	default:
+		return Invalid
+	}
+}
+
+// OneOf takes a struct and the name of a union, returns the tag and value of its field
+// that is set, zero and nil if none is.
+func OneOf(v interface{}, name string) (int, interface{}) {
+	value := Value(v)
+
+	if value.Kind() != reflect.Struct {
+		return 0, nil
+	}
+
+	fields := typeFields(value.Type())
+
+	for _, i := range fields.oneofs[name] {
+		f := fields.list[i]
+
+		if field := value.Field(f.index); !field.IsZero() {
+			return f.tag, field.Interface()
+		}
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,83 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+type Option func(*options)
+
+type options struct {
+	fixed  bool
+	packed bool
+	delta  bool
+	stream bool
+	enums  bool
+	mask   FieldMask
+}
+
+// apply returns the options with opts applied on top of them.
+func (o options) apply(opts []Option) options {
+	for _, option := range opts {
+		option(&o)
+	}
+
+	return o
+}
+
+// with returns the options enabled by a field tag on top of the current ones.
+func (o options) with(f field) options {
+	o.fixed = o.fixed || f.fixed
+	o.packed = o.packed || f.packed
+	o.delta = o.delta || f.delta
+	o.stream = o.stream || f.stream
+
+	return o
+}
+
+// exact reports if zero values must be written as they are instead of a single zero.
+func (o options) exact() bool {
+	return o.fixed || o.packed || o.delta
+}
+
+// Fixed encodes floats and complexes as little endian words instead of VarInts.
+func Fixed() Option {
+	return func(o *options) {
+		o.fixed = true
+	}
+}
+
+// Packed encodes arrays and slices of booleans as bits instead of bytes.
+func Packed() Option {
+	return func(o *options) {
+		o.packed = true
+	}
+}
+
+// Delta encodes arrays and slices of integers as differences from the previous integer.
+func Delta() Option {
+	return func(o *options) {
+		o.delta = true
+	}
+}
+
+// Stream encodes channels as a stream of their elements until they are closed.
+func Stream() Option {
+	return func(o *options) {
+		o.stream = true
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"github.com/Dviih/bin/kind"
+	"io"
+	"reflect"
+)
+
+// Kind used as the element of interfaced arrays and slices of packed booleans.
+const kindPackedBool = 74
+
+func packable(kinds *kind.Map, t reflect.Type) bool {
+	if t.Kind() != reflect.Bool {
+		return false
+	}
+
+	return !registered(kinds, t)
+}
+
+// elem writes the kind of array and slice elements.
+func (encoder *Encoder) elem(t reflect.Type) error {
+	if encoder.options.packed && packable(encoder.codec.kinds, t) {
+		return VarIntIn(encoder.writer, kindPackedBool)
+	}
+
+	if encoder.options.delta && deltable(encoder.codec.kinds, t) {
+		if err := VarIntIn(encoder.writer, kindDelta); err != nil {
+			return err
+		}
+	}
+
+	return VarIntIn(encoder.writer, encoder.kind(t))
+}
+
+// packed writes booleans as bits, the first boolean being the lowest bit of the first byte.
+func (encoder *Encoder) packed(value reflect.Value) (bool, error) {
+	if !encoder.options.packed || !packable(encoder.codec.kinds, value.Type().Elem()) {
+		return false, nil
+	}
+
+	b := encoder.scratch[:0]
+
+	for i := 0; i < value.Len(); i += 8 {
+		var c byte
+
+		for j := 0; j < 8 && i+j < value.Len(); j++ {
+			if value.Index(i + j).Bool() {
+				c |= 1 << j
+			}
+		}
+
+		b = append(b, c)
+	}
+
+	encoder.scratch = b[:0]
+
+	_, err := encoder.writer.Write(b)
+	return true, err
+}
+
+func (decoder *Decoder) packed(value reflect.Value) (bool, error) {
+	if !decoder.options.packed || !packable(decoder.codec.kinds, value.Type().Elem()) {
+		return false, nil
+	}
+
+	b := make([]byte, (value.Len()+7)/8)
+
+	if _, err := io.ReadFull(decoder.reader, b); err != nil {
+		return true, err
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		value.Index(i).SetBool(b[i/8]&(1<<(i%8)) != 0)
+	}
+
+	return true, nil
+}
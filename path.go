@@ -0,0 +1,322 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Path returns the value at path, nil if it's not found or path is invalid.
+func (structs *Struct) Path(path string) interface{} {
+	v, _, _ := structs.Lookup(path)
+	return v
+}
+
+// Lookup follows a path of tags separated by dots, `[i]` indexes arrays and slices or
+// matches map keys by how they are printed, as in `20.3.[2].7`.
+func (structs *Struct) Lookup(path string) (interface{}, bool, error) {
+	value := reflect.ValueOf(structs)
+
+	if path == "" {
+		return structs, true, nil
+	}
+
+	for _, segment := range segments(path) {
+		if value = deref(value); !value.IsValid() {
+			return nil, false, nil
+		}
+
+		next, ok, err := step(value, segment)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+
+		value = next
+	}
+
+	return value.Interface(), true, nil
+}
+
+// segments splits a path by dots outside of brackets.
+func segments(path string) []string {
+	var list []string
+
+	if path == "" {
+		return list
+	}
+
+	start, depth := 0, 0
+
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				list = append(list, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(list, path[start:])
+}
+
+// index returns what is between brackets, or the segment itself.
+func index(segment string) string {
+	if strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]") {
+		return segment[1 : len(segment)-1]
+	}
+
+	return segment
+}
+
+func step(value reflect.Value, segment string) (reflect.Value, bool, error) {
+	if strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]") {
+		key := index(segment)
+
+		switch value.Kind() {
+		case reflect.Array, reflect.Slice:
+			i, err := strconv.Atoi(key)
+			if err != nil {
+				return reflect.Value{}, false, fmt.Errorf("%w: %q is not an index", InvalidPath, segment)
+			}
+
+			if i < 0 || i >= value.Len() {
+				return reflect.Value{}, false, nil
+			}
+
+			return value.Index(i), true, nil
+		case reflect.Map:
+			m := value.MapRange()
+
+			for m.Next() {
+				if fmt.Sprint(m.Key().Interface()) == key {
+					return m.Value(), true, nil
+				}
+			}
+
+			return reflect.Value{}, false, nil
+		}
+	} else if value.Type() == reflect.TypeFor[Struct]() {
+		tag, err := strconv.Atoi(segment)
+		if err != nil {
+			return reflect.Value{}, false, fmt.Errorf("%w: %q is not a tag", InvalidPath, segment)
+		}
+
+		v, ok := value.Interface().(Struct).m[tag]
+		return v, ok, nil
+	}
+
+	return reflect.Value{}, false, fmt.Errorf("%w: %q on %v", InvalidPath, segment, value.Type())
+}
+
+// Walk calls fn depth first for every value under the struct with its path as taken by Lookup,
+// nested structs, arrays, slices and maps are called before their values, map keys are sorted as printed
+// and byte slices are a single value. The path is only valid until fn returns, copy it to keep it.
+// Returning false from fn stops the walk.
+func (structs *Struct) Walk(fn func(path []byte, v interface{}) bool) {
+	w := &walker{
+		fn: fn,
+	}
+
+	w.walk(reflect.ValueOf(structs))
+}
+
+// walker keeps the path and what is needed to sort tags and keys, reused by every value of a walk.
+type walker struct {
+	fn    func([]byte, interface{}) bool
+	path  []byte
+	tags  []int
+	names []byte
+	ends  []int
+	order []int
+}
+
+// deref follows pointers and interfaces without setting them, nil ones are invalid.
+func deref(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
+
+// visit calls fn with the path joined with what segment appends and walks under v.
+func (w *walker) visit(v reflect.Value, segment func([]byte) []byte) bool {
+	n := len(w.path)
+	defer func() {
+		w.path = w.path[:n]
+	}()
+
+	if n > 0 {
+		w.path = append(w.path, '.')
+	}
+
+	w.path = segment(w.path)
+
+	var i interface{}
+	if v.IsValid() {
+		i = v.Interface()
+	}
+
+	return w.fn(w.path, i) && w.walk(v)
+}
+
+func (w *walker) walk(value reflect.Value) bool {
+	value = deref(value)
+
+	if !value.IsValid() {
+		return true
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() != reflect.TypeFor[Struct]() {
+			return true
+		}
+
+		var s *Struct
+		if value.CanAddr() {
+			s = value.Addr().Interface().(*Struct)
+		} else {
+			c := value.Interface().(Struct)
+			s = &c
+		}
+
+		base := len(w.tags)
+		defer func() {
+			w.tags = w.tags[:base]
+		}()
+
+		for tag := range s.m {
+			w.tags = append(w.tags, tag)
+		}
+
+		slices.Sort(w.tags[base:])
+
+		for _, tag := range w.tags[base:] {
+			if !w.visit(s.m[tag], func(b []byte) []byte {
+				return strconv.AppendInt(b, int64(tag), 10)
+			}) {
+				return false
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if !w.visit(value.Index(i), func(b []byte) []byte {
+				return append(strconv.AppendInt(append(b, '['), int64(i), 10), ']')
+			}) {
+				return false
+			}
+		}
+	case reflect.Map:
+		return w.maps(value)
+	}
+
+	return true
+}
+
+// maps walks a map with its keys sorted as printed, keys are printed once into names and
+// keys and values are copied into a slice each, so entries don't allocate one by one.
+func (w *walker) maps(value reflect.Value) bool {
+	base, start := len(w.ends), len(w.names)
+	defer func() {
+		w.ends, w.order, w.names = w.ends[:base], w.order[:base], w.names[:start]
+	}()
+
+	keys := reflect.MakeSlice(reflect.SliceOf(value.Type().Key()), value.Len(), value.Len())
+	values := reflect.MakeSlice(reflect.SliceOf(value.Type().Elem()), value.Len(), value.Len())
+
+	m := value.MapRange()
+
+	for i := 0; m.Next(); i++ {
+		keys.Index(i).SetIterKey(m)
+		values.Index(i).SetIterValue(m)
+
+		w.order = append(w.order, i)
+		w.names = appendKey(w.names, keys.Index(i))
+		w.ends = append(w.ends, len(w.names))
+	}
+
+	name := func(i int) []byte {
+		from := start
+		if i > 0 {
+			from = w.ends[base+i-1]
+		}
+
+		return w.names[from:w.ends[base+i]]
+	}
+
+	slices.SortFunc(w.order[base:], func(a, b int) int {
+		return bytes.Compare(name(a), name(b))
+	})
+
+	for j := base; j < len(w.order); j++ {
+		i := w.order[j]
+
+		if !w.visit(values.Index(i), func(b []byte) []byte {
+			return append(append(append(b, '['), name(i)...), ']')
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// appendKey appends key as printed by fmt.
+func appendKey(b []byte, key reflect.Value) []byte {
+	if key.Kind() == reflect.Interface && !key.IsNil() {
+		key = key.Elem()
+	}
+
+	if key.Type().Implements(reflect.TypeFor[fmt.Stringer]()) || key.Type().Implements(reflect.TypeFor[error]()) {
+		return fmt.Append(b, key.Interface())
+	}
+
+	switch key.Kind() {
+	case reflect.String:
+		return append(b, key.String()...)
+	case reflect.Bool:
+		return strconv.AppendBool(b, key.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.AppendInt(b, key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.AppendUint(b, key.Uint(), 10)
+	default:
+		return fmt.Append(b, key.Interface())
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"io"
+	"iter"
+)
+
+// reading is an io.Reader that counts what is read through it.
+type reading struct {
+	reader io.Reader
+	n      int
+}
+
+func (r *reading) Read(data []byte) (int, error) {
+	n, err := r.reader.Read(data)
+	r.n += n
+
+	return n, err
+}
+
+func (r *reading) ReadByte() (byte, error) {
+	b, err := readByte(r.reader)
+	if err == nil {
+		r.n++
+	}
+
+	return b, err
+}
+
+// Values decodes consecutive values until the reader is done, a value cut short yields io.ErrUnexpectedEOF.
+func Values[T interface{}](decoder *Decoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		r := &reading{
+			reader: decoder.reader,
+		}
+
+		decoder.reader = r
+		defer func() {
+			decoder.reader = r.reader
+		}()
+
+		for {
+			var t T
+			n := r.n
+
+			err := decoder.Decode(&t)
+
+			switch {
+			case r.n == n && (err == nil || err == io.EOF):
+				return
+			case err == io.EOF:
+				err = io.ErrUnexpectedEOF
+			}
+
+			if err != nil {
+				var zero T
+				yield(zero, err)
+
+				return
+			}
+
+			if !yield(t, nil) {
+				return
+			}
+		}
+	}
+}
+
+// EncodeSeq writes each value of seq as a stream, decode it with DecodeSeq or into a channel with Stream.
+func EncodeSeq[T interface{}](encoder *Encoder, seq iter.Seq[T]) error {
+	var err error
+
+	seq(func(t T) bool {
+		if err = encoder.done(); err != nil {
+			return false
+		}
+
+		if err = encoder.writeByte(1); err != nil {
+			return false
+		}
+
+		err = encoder.Encode(t)
+		return err == nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return encoder.writeByte(0)
+}
+
+// DecodeSeq decodes the values of a stream as written by EncodeSeq until its terminator,
+// a stream cut short yields io.ErrUnexpectedEOF.
+func DecodeSeq[T interface{}](decoder *Decoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			n, err := VarIntOut[int](decoder.reader)
+			if err == nil && n == 0 {
+				return
+			}
+
+			for i := 0; err == nil && i < n; i++ {
+				if err = decoder.done(); err != nil {
+					break
+				}
+
+				var t T
+
+				if err = decoder.Decode(&t); err != nil {
+					break
+				}
+
+				if !yield(t, nil) {
+					return
+				}
+			}
+
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			if err != nil {
+				var zero T
+				yield(zero, err)
+
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+// counter is an io.Writer that only counts what is written to it.
+type counter struct {
+	n int
+}
+
+func (c *counter) Write(data []byte) (int, error) {
+	c.n += len(data)
+	return len(data), nil
+}
+
+func (c *counter) WriteByte(byte) error {
+	c.n++
+	return nil
+}
+
+func (c *counter) WriteString(s string) (int, error) {
+	c.n += len(s)
+	return len(s), nil
+}
+
+// Size returns how many bytes Marshal would produce for v, pass Interface(v) for the interface size,
+// returns CantSize for channels encoded as streams as sizing them would drain them.
+func Size(v interface{}) (int, error) {
+	return std.Size(v)
+}
+
+// Size is the same as the package function using the codec.
+func (codec *Codec) Size(v interface{}) (int, error) {
+	c := &counter{}
+
+	if err := codec.NewEncoder(c).Encode(v); err != nil {
+		return 0, err
+	}
+
+	return c.n, nil
+}
@@ -0,0 +1,182 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import "reflect"
+
+// Kind used by interfaced channels encoded as streams, followed by the kind of their elements.
+const kindStream = 86
+
+// stream drains a channel until it is closed, writing chunks of the elements
+// ready at the time and a zero chunk at the end.
+func (encoder *Encoder) stream(value reflect.Value) error {
+	if value.Type().ChanDir()&reflect.RecvDir == 0 {
+		return Invalid
+	}
+
+	// Sizing would drain the channel, leaving nothing to be encoded after.
+	if _, ok := encoder.writer.(*counter); ok {
+		return CantSize
+	}
+
+	if value.IsNil() {
+		return encoder.writeByte(0)
+	}
+
+	var chunk []reflect.Value
+
+	for {
+		v, ok, err := encoder.recv(value)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return encoder.writeByte(0)
+		}
+
+		chunk = append(chunk[:0], v)
+
+		for n := value.Len(); n > 0; n-- {
+			v, ok := value.TryRecv()
+			if !ok {
+				break
+			}
+
+			chunk = append(chunk, v)
+		}
+
+		if err := VarIntIn(encoder.writer, len(chunk)); err != nil {
+			return err
+		}
+
+		for _, v := range chunk {
+			if err := encoder.done(); err != nil {
+				return err
+			}
+
+			if err := encoder.encode(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stream sends elements to a channel as they arrive and closes it once the stream ends or fails,
+// a nil channel is only made at the end with room for every element.
+func (decoder *Decoder) stream(value reflect.Value) error {
+	if !value.IsNil() {
+		if value.Type().ChanDir()&reflect.SendDir == 0 {
+			return Invalid
+		}
+
+		defer value.Close()
+		return decoder.chunks(value.Type().Elem(), func(v reflect.Value) error {
+			return decoder.send(value, v)
+		})
+	}
+
+	var list []reflect.Value
+
+	if err := decoder.chunks(value.Type().Elem(), func(v reflect.Value) error {
+		list = append(list, v)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	c := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, value.Type().Elem()), len(list))
+
+	for _, v := range list {
+		c.Send(v)
+	}
+
+	c.Close()
+
+	value.Set(c.Convert(value.Type()))
+	return nil
+}
+
+func (decoder *Decoder) chunks(t reflect.Type, fn func(reflect.Value) error) error {
+	for {
+		n, err := VarIntOut[int](decoder.reader)
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return nil
+		}
+
+		for i := 0; i < n; i++ {
+			if err = decoder.done(); err != nil {
+				return err
+			}
+
+			v := reflect.New(t).Elem()
+
+			if err = decoder.decode(v); err != nil {
+				return err
+			}
+
+			if err = fn(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// recv receives from a channel, giving up once the context of the encoder is done.
+func (encoder *Encoder) recv(value reflect.Value) (reflect.Value, bool, error) {
+	if encoder.ctx == nil {
+		v, ok := value.Recv()
+		return v, ok, nil
+	}
+
+	chosen, v, ok := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: value},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(encoder.ctx.Done())},
+	})
+
+	if chosen == 1 {
+		return v, false, encoder.ctx.Err()
+	}
+
+	return v, ok, nil
+}
+
+// send sends to a channel, giving up once the context of the decoder is done.
+func (decoder *Decoder) send(value, v reflect.Value) error {
+	if decoder.ctx == nil {
+		value.Send(v)
+		return nil
+	}
+
+	chosen, _, _ := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: value, Send: v},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(decoder.ctx.Done())},
+	})
+
+	if chosen == 1 {
+		return decoder.ctx.Err()
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// FieldError is a value that could not be set, Path is the same as taken by Struct.Lookup.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// AsStrict is As reporting every field that could not be set as a FieldError, see TryAs for the conversions.
+func (structs *Struct) AsStrict(v interface{}) error {
+	value := reflect.ValueOf(v)
+
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return CantSet
+	}
+
+	var errs []error
+	strict(reflect.ValueOf(structs), value.Elem(), "", &errs)
+
+	return errors.Join(errs...)
+}
+
+// TryAs is As reporting every value that could not be set as a FieldError, T has every value that could.
+// Only conversions without loss are made:
+//   - integers into integers and floats holding them exactly,
+//   - floats into floats and complexes holding them exactly,
+//   - arrays, slices and maps element by element, arrays must have room for every element,
+//   - structs into structs by tag.
+func TryAs[T interface{}](v interface{}) (T, error) {
+	var t T
+	var errs []error
+
+	strict(reflect.ValueOf(v), reflect.ValueOf(&t).Elem(), "", &errs)
+
+	return t, errors.Join(errs...)
+}
+
+func strict(src, dst reflect.Value, path string, errs *[]error) {
+	for src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return
+		}
+
+		src = src.Elem()
+	}
+
+	if !src.IsValid() {
+		return
+	}
+
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+
+		strict(src, dst.Elem(), path, errs)
+		return
+	}
+
+	if src.Kind() == reflect.Pointer {
+		if !src.IsNil() {
+			strict(src.Elem(), dst, path, errs)
+		}
+
+		return
+	}
+
+	fail := func(err error) {
+		*errs = append(*errs, &FieldError{
+			Path: path,
+			Err:  fmt.Errorf("%w: %v into %v", err, src.Type(), dst.Type()),
+		})
+	}
+
+	join := func(segment string) string {
+		if path == "" {
+			return segment
+		}
+
+		return path + "." + segment
+	}
+
+	switch dst.Kind() {
+	case reflect.Bool:
+		if src.Kind() != reflect.Bool {
+			fail(Mismatch)
+			return
+		}
+
+		dst.SetBool(src.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if dst.OverflowInt(src.Int()) {
+				fail(Overflow)
+				return
+			}
+
+			dst.SetInt(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if src.Uint() > math.MaxInt64 || dst.OverflowInt(int64(src.Uint())) {
+				fail(Overflow)
+				return
+			}
+
+			dst.SetInt(int64(src.Uint()))
+		default:
+			fail(Mismatch)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if src.Int() < 0 || dst.OverflowUint(uint64(src.Int())) {
+				fail(Overflow)
+				return
+			}
+
+			dst.SetUint(uint64(src.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if dst.OverflowUint(src.Uint()) {
+				fail(Overflow)
+				return
+			}
+
+			dst.SetUint(src.Uint())
+		default:
+			fail(Mismatch)
+		}
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		var c complex128
+
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f := float64(src.Int())
+
+			if f >= math.MaxInt64 || int64(f) != src.Int() {
+				fail(Overflow)
+				return
+			}
+
+			c = complex(f, 0)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f := float64(src.Uint())
+
+			if f >= math.MaxUint64 || uint64(f) != src.Uint() {
+				fail(Overflow)
+				return
+			}
+
+			c = complex(f, 0)
+		case reflect.Float32, reflect.Float64:
+			c = complex(src.Float(), 0)
+		case reflect.Complex64, reflect.Complex128:
+			if dst.Kind() == reflect.Float32 || dst.Kind() == reflect.Float64 {
+				fail(Mismatch)
+				return
+			}
+
+			c = src.Complex()
+		default:
+			fail(Mismatch)
+			return
+		}
+
+		switch dst.Kind() {
+		case reflect.Float32:
+			if !exact(real(c)) {
+				fail(Overflow)
+				return
+			}
+
+			dst.SetFloat(real(c))
+		case reflect.Float64:
+			dst.SetFloat(real(c))
+		case reflect.Complex64:
+			if !exact(real(c)) || !exact(imag(c)) {
+				fail(Overflow)
+				return
+			}
+
+			dst.SetComplex(c)
+		case reflect.Complex128:
+			dst.SetComplex(c)
+		}
+	case reflect.String:
+		if src.Kind() != reflect.String {
+			fail(Mismatch)
+			return
+		}
+
+		dst.SetString(src.String())
+	case reflect.Array, reflect.Slice:
+		if src.Kind() != reflect.Array && src.Kind() != reflect.Slice {
+			fail(Mismatch)
+			return
+		}
+
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+		} else if src.Len() > dst.Len() {
+			fail(Overflow)
+			return
+		}
+
+		for i := 0; i < src.Len(); i++ {
+			strict(src.Index(i), dst.Index(i), join("["+strconv.Itoa(i)+"]"), errs)
+		}
+	case reflect.Map:
+		if src.Kind() != reflect.Map {
+			fail(Mismatch)
+			return
+		}
+
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+
+		m := src.MapRange()
+
+		for m.Next() {
+			p := join("[" + fmt.Sprint(m.Key().Interface()) + "]")
+			k := reflect.New(dst.Type().Key()).Elem()
+			v := reflect.New(dst.Type().Elem()).Elem()
+
+			n := len(*errs)
+
+			strict(m.Key(), k, p, errs)
+			strict(m.Value(), v, p, errs)
+
+			if len(*errs) == n {
+				dst.SetMapIndex(k, v)
+			}
+		}
+	case reflect.Struct:
+		if src.Type() != reflect.TypeFor[Struct]() {
+			fail(Mismatch)
+			return
+		}
+
+		s := src.Interface().(Struct)
+
+		for _, f := range typeFields(dst.Type()).list {
+			v, ok := s.m[f.tag]
+			if !ok {
+				continue
+			}
+
+			strict(v, dst.Field(f.index), join(strconv.Itoa(f.tag)), errs)
+		}
+	default:
+		fail(Mismatch)
+	}
+}
+
+// exact reports if f is the same as a float32.
+func exact(f float64) bool {
+	return math.IsNaN(f) || float64(float32(f)) == f
+}
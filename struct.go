@@ -20,15 +20,70 @@
 package bin
 
 import (
+	"iter"
 	"reflect"
-	"strconv"
+	"sort"
 )
 
 // Struct represents any struct.
 type Struct struct {
-	m map[int]reflect.Value
+	m     map[int]reflect.Value
+	codec *Codec
 }
 
+func NewStruct() *Struct {
+	return &Struct{
+		m: make(map[int]reflect.Value),
+	}
+}
+
+// Set sets the value of a tag, a nil value deletes it.
+func (structs *Struct) Set(tag int, v interface{}) *Struct {
+	if v == nil {
+		return structs.Delete(tag)
+	}
+
+	if structs.m == nil {
+		structs.m = make(map[int]reflect.Value)
+	}
+
+	structs.m[tag] = reflect.ValueOf(v)
+	return structs
+}
+
+func (structs *Struct) Delete(tag int) *Struct {
+	delete(structs.m, tag)
+	return structs
+}
+
+// Tags returns the tags in ascending order.
+func (structs *Struct) Tags() []int {
+	tags := make([]int, 0, len(structs.m))
+
+	for tag := range structs.m {
+		tags = append(tags, tag)
+	}
+
+	sort.Ints(tags)
+	return tags
+}
+
+func (structs *Struct) Len() int {
+	return len(structs.m)
+}
+
+// All yields tags and their values in ascending tag order.
+func (structs *Struct) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		for _, tag := range structs.Tags() {
+			if !yield(tag, structs.m[tag].Interface()) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns the struct as a map, values of enums are shown by their names from the codec that decoded the struct.
 func (structs *Struct) Map() map[interface{}]interface{} {
 	return structs.maps(reflect.ValueOf(structs.m))
 }
@@ -58,17 +113,88 @@ func (structs *Struct) maps(old reflect.Value) map[interface{}]interface{} {
 				continue
 			}
 
-			m[r.Key().Interface()] = v.Interface()
+			m[r.Key().Interface()] = structs.names(v.Interface())
 		case []interface{}:
 			m[r.Key()] = structs.arrays(Abs[reflect.Value](r.Value()))
 		default:
-			m[r.Key().Interface()] = v
+			m[r.Key().Interface()] = structs.names(v)
+		}
+	}
+
+	return m
+}
+
+// MapOf is the same as Map taking t, the type the struct was encoded from, so values of its enum fields,
+// written as their integers, are shown by their names.
+func (structs *Struct) MapOf(t reflect.Type) map[interface{}]interface{} {
+	m := structs.Map()
+
+	t = Abs[reflect.Type](t)
+	if t.Kind() != reflect.Struct {
+		return m
+	}
+
+	for _, f := range typeFields(t).list {
+		if v, ok := structs.m[f.tag]; ok {
+			m[f.tag] = structs.hint(Abs[reflect.Value](v), t.Field(f.index).Type, m[f.tag])
 		}
 	}
 
 	return m
 }
 
+// hint returns v as shown by Map when it was encoded from t, or shown as it is.
+func (structs *Struct) hint(v reflect.Value, t reflect.Type, shown interface{}) interface{} {
+	t = Abs[reflect.Type](t)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := structs.source().enums.Load(t); ok {
+			return structs.names(v.Convert(t).Interface())
+		}
+	case reflect.Array, reflect.Slice:
+		if t.Kind() != reflect.Array && t.Kind() != reflect.Slice {
+			return shown
+		}
+
+		elem := Abs[reflect.Type](t.Elem())
+
+		if _, ok := structs.source().enums.Load(elem); !ok && !isStruct(structs.source().kinds, elem) {
+			return shown
+		}
+
+		list := make([]interface{}, v.Len())
+
+		for i := range list {
+			e := Abs[reflect.Value](v.Index(i))
+			list[i] = structs.hint(e, elem, e.Interface())
+		}
+
+		return list
+	case reflect.Struct:
+		if s, ok := v.Interface().(Struct); ok {
+			return s.MapOf(t)
+		}
+	}
+
+	return shown
+}
+
+// source returns the codec that decoded the struct, the package codec for structs not decoded.
+func (structs *Struct) source() *Codec {
+	if structs.codec == nil {
+		return std
+	}
+
+	return structs.codec
+}
+
+// names returns the name of v if it is an enum.
+func (structs *Struct) names(v interface{}) interface{} {
+	return structs.source().enumNames(v)
+}
+
 func (structs *Struct) arrays(value reflect.Value) []interface{} {
 	var m []interface{}
 
@@ -82,7 +208,7 @@ func (structs *Struct) arrays(value reflect.Value) []interface{} {
 			s := element.Interface().(Struct)
 			m = append(m, s.Map())
 		default:
-			m = append(m, element.Interface())
+			m = append(m, structs.names(element.Interface()))
 		}
 	}
 
@@ -120,27 +246,9 @@ func (structs *Struct) As(v interface{}) {
 
 func (structs *Struct) fields(value reflect.Value) map[int]reflect.Value {
 	fields := make(map[int]reflect.Value)
-	typ := value.Type()
-
-	for i := 0; i < value.NumField(); i++ {
-		fieldType := typ.Field(i)
-
-		if !fieldType.IsExported() {
-			continue
-		}
-
-		lookup, ok := fieldType.Tag.Lookup("bin")
-		if !ok {
-			fields[i+1] = value.Field(i)
-			continue
-		}
-
-		tag, err := strconv.Atoi(lookup)
-		if err != nil {
-			continue
-		}
 
-		fields[tag] = value.Field(i)
+	for _, f := range typeFields(value.Type()).list {
+		fields[f.tag] = value.Field(f.index)
 	}
 
 	return fields
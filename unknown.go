@@ -0,0 +1,187 @@
+/*
+ *     A tiny binary format
+ *     Copyright (C) 2025  Dviih
+ *
+ *     This program is free software: you can redistribute it and/or modify
+ *     it under the terms of the GNU Affero General Public License as published
+ *     by the Free Software Foundation, either version 3 of the License, or
+ *     (at your option) any later version.
+ *
+ *     This program is distributed in the hope that it will be useful,
+ *     but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *     MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *     GNU Affero General Public License for more details.
+ *
+ *     You should have received a copy of the GNU Affero General Public License
+ *     along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package bin
+
+import (
+	"fmt"
+	"github.com/Dviih/bin/buffer"
+	"io"
+	"reflect"
+	"slices"
+)
+
+// Unknown keeps the raw bytes of tags a struct doesn't have, a struct with an Unknown
+// field is written with a count and the length of every field so they can be skipped.
+type Unknown map[int][]byte
+
+// framedMark starts framed structs, it is a zero no encoder writes as a tag, so a struct
+// written before it had an Unknown field is rejected instead of misread.
+var framedMark = []byte{0x80, 0}
+
+// Tags returns the unknown tags in ascending order.
+func (unknown Unknown) Tags() []int {
+	tags := make([]int, 0, len(unknown))
+
+	for tag := range unknown {
+		tags = append(tags, tag)
+	}
+
+	slices.Sort(tags)
+	return tags
+}
+
+// extra returns the unknown tags the struct doesn't have a field for.
+func (unknown Unknown) extra(fields *fields) []int {
+	return slices.DeleteFunc(unknown.Tags(), func(tag int) bool {
+		_, ok := fields.tags[tag]
+		return ok
+	})
+}
+
+// unknown writes the count of fields when head is set, otherwise the unknown tags and their bytes.
+func (encoder *Encoder) unknown(value reflect.Value, fields *fields, head bool) error {
+	unknown := value.Field(fields.unknown).Interface().(Unknown)
+	extra := unknown.extra(fields)
+
+	if head {
+		if _, err := encoder.writer.Write(framedMark); err != nil {
+			return err
+		}
+
+		return VarIntIn(encoder.writer, len(fields.list)+len(extra))
+	}
+
+	for _, tag := range extra {
+		if err := VarIntIn(encoder.writer, tag); err != nil {
+			return err
+		}
+
+		if err := VarIntIn(encoder.writer, len(unknown[tag])); err != nil {
+			return err
+		}
+
+		if _, err := encoder.writer.Write(unknown[tag]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// frame writes what fn encodes prefixed by its length.
+func (encoder *Encoder) frame(fn func() error) error {
+	writer := encoder.writer
+	b := buffer.New()
+
+	encoder.writer = b
+	err := fn()
+	encoder.writer = writer
+
+	if err != nil {
+		return err
+	}
+
+	if err = VarIntIn(encoder.writer, b.Len()); err != nil {
+		return err
+	}
+
+	_, err = encoder.writer.Write(b.Data())
+	return err
+}
+
+// framed reads a struct with an Unknown field, keeping the bytes of tags it doesn't have.
+func (decoder *Decoder) framed(value reflect.Value, fields *fields) (bool, error) {
+	for i, mark := range framedMark {
+		b, err := readByte(decoder.reader)
+		if err != nil {
+			return true, err
+		}
+
+		// A zero struct is written as a single zero instead of being framed.
+		if i == 0 && b == 0 {
+			return false, nil
+		}
+
+		if b != mark {
+			return true, fmt.Errorf("%w: %v", NotFramed, value.Type())
+		}
+	}
+
+	size, err := VarIntOut[int](decoder.reader)
+	if err != nil {
+		return true, err
+	}
+
+	var unknown Unknown
+
+	for i := 0; i < size; i++ {
+		if err = decoder.done(); err != nil {
+			return true, err
+		}
+
+		tag, err := VarIntOut[int](decoder.reader)
+		if err != nil {
+			return true, err
+		}
+
+		n, err := VarIntOut[int](decoder.reader)
+		if err != nil {
+			return true, err
+		}
+
+		if n < 0 || n > buffer.MaxSize {
+			return true, fmt.Errorf("%w: frame of %d bytes", Invalid, n)
+		}
+
+		// Reading as it arrives doesn't allocate the whole length for a short input.
+		data, err := io.ReadAll(io.LimitReader(decoder.reader, int64(n)))
+		if err != nil {
+			return true, err
+		}
+
+		if len(data) != n {
+			return true, io.ErrUnexpectedEOF
+		}
+
+		f, ok := fields.tags[tag]
+		if !ok {
+			if unknown == nil {
+				unknown = Unknown{}
+			}
+
+			unknown[tag] = data
+			continue
+		}
+
+		reader := decoder.reader
+		decoder.reader = buffer.From(data)
+
+		err = decoder.field(value, fields.list[f])
+
+		decoder.reader = reader
+
+		if err != nil {
+			return true, err
+		}
+	}
+
+	value.Field(fields.unknown).Set(reflect.ValueOf(unknown))
+	return true, fields.oneof(value)
+}
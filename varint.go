@@ -21,7 +21,6 @@ package bin
 
 import (
 	"io"
-	"reflect"
 	"unsafe"
 )
 
@@ -30,59 +29,53 @@ type Integer = interface {
 }
 
 func VarIntIn[T Integer](writer io.Writer, t T) error {
-	b := make([]byte, 0)
+	// Writing byte by byte keeps b on the stack, passing it to Write makes it escape.
+	if bw, ok := writer.(io.ByteWriter); ok {
+		var b [10]byte
 
-	switch reflect.ValueOf(t).Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		for int64(t) >= 0x80 {
-			b = append(b, byte(t)|0x80)
-			t >>= 7
-		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		for uint64(t) >= 0x80 {
-			b = append(b, byte(t)|0x80)
-			t >>= 7
+		for _, c := range appendVarInt(b[:0], t) {
+			if err := bw.WriteByte(c); err != nil {
+				return err
+			}
 		}
-	default:
-		panic("impossible")
+
+		return nil
 	}
 
-	b = append(b, byte(t))
+	var b [10]byte
 
-	if _, err := writer.Write(b); err != nil {
+	if _, err := writer.Write(appendVarInt(b[:0], t)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func VarIntOut[T Integer](reader io.Reader) (T, error) {
-	var br func() (byte, error)
+func appendVarInt[T Integer](b []byte, t T) []byte {
+	var zero T
 
-	if rbr, ok := reader.(io.ByteReader); ok {
-		br = rbr.ReadByte
+	// ^zero is only negative for signed integers.
+	if ^zero < zero {
+		for int64(t) >= 0x80 {
+			b = append(b, byte(t)|0x80)
+			t >>= 7
+		}
 	} else {
-		br = func() (byte, error) {
-			b := [1]byte{}
-
-			n, err := reader.Read(b[:])
-			if err != nil {
-				return 0, err
-			}
-
-			if n != 1 {
-				return 0, io.EOF
-			}
-
-			return b[0], nil
+		for uint64(t) >= 0x80 {
+			b = append(b, byte(t)|0x80)
+			t >>= 7
 		}
 	}
 
+	return append(b, byte(t))
+}
+
+func VarIntOut[T Integer](reader io.Reader) (T, error) {
 	var t T
 	var p uint64
 
 	for i := 0; i < 10; i++ {
-		b, err := br()
+		b, err := readByte(reader)
 		if err != nil {
 			return 0, err
 		}
@@ -102,16 +95,23 @@ func VarIntOut[T Integer](reader io.Reader) (T, error) {
 	return 0, io.EOF
 }
 
-func floatToBits[F float32 | float64](f F) interface{} {
-	switch any(f).(type) {
-	case float32:
-		return *(*uint32)(unsafe.Pointer(&f))
-	case float64:
-		return *(*uint64)(unsafe.Pointer(&f))
-	default:
-		// this should never be reached
-		panic("invalid")
+func readByte(reader io.Reader) (byte, error) {
+	if br, ok := reader.(io.ByteReader); ok {
+		return br.ReadByte()
 	}
+
+	b := [1]byte{}
+
+	n, err := reader.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+
+	if n != 1 {
+		return 0, io.EOF
+	}
+
+	return b[0], nil
 }
 
 func floatFromBits[V uint32 | uint64](v V) float64 {